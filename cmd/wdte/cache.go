@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/DeedleFake/wdte"
+	"github.com/DeedleFake/wdte/importer"
+)
+
+// compile compiles src into a program. If dir is not empty, compiled
+// programs are cached in it in files named after the hash of their
+// source, the version of WDTE, and the program format, and src is only
+// compiled if a usable cached program isn't found. Failures to read or
+// write the cache are ignored.
+func compile(src []byte, dir string) (*wdte.Program, error) {
+	if dir == "" {
+		return wdte.Compile(bytes.NewReader(src), nil)
+	}
+
+	path := filepath.Join(dir, cacheKey(src)+".wdtec")
+
+	if data, err := os.ReadFile(path); err == nil {
+		var p wdte.Program
+		if err := p.UnmarshalBinary(data); err == nil {
+			return &p, nil
+		}
+	}
+
+	p, err := wdte.Compile(bytes.NewReader(src), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := p.MarshalBinary(); err == nil {
+		writeCache(path, data)
+	}
+
+	return p, nil
+}
+
+// cacheKey returns the name that the compiled form of src is cached
+// under. Besides the source itself, it depends on anything that can
+// change how the source compiles or how the result is encoded, so
+// that upgrading WDTE doesn't cause stale programs to be loaded.
+func cacheKey(src []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%v\x00%v\x00", importer.Version(), wdte.ProgramFormat)
+	h.Write(src)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeCache atomically writes data to path, creating its directory
+// if necessary.
+func writeCache(path string, data []byte) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(dir, "*.tmp")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return
+	}
+
+	os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/DeedleFake/wdte"
+	"github.com/DeedleFake/wdte/debugger"
+	"github.com/DeedleFake/wdte/std"
+)
+
+// debugScript runs the script read from file under the control of an
+// interactive debugger that reads commands from stdin. Like file, it
+// caches the compiled script in cacheDir if it isn't empty.
+func debugScript(im wdte.Importer, file io.Reader, cacheDir string) {
+	src, err := io.ReadAll(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read script: %v", err)
+		os.Exit(1)
+	}
+
+	p, err := compile(src, cacheDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to parse script: %v", err)
+		os.Exit(1)
+	}
+
+	d := debugger.New(os.Stdin, os.Stdout)
+	r := d.Run(std.F().WithImporter(im), p.Compound())
+	if err, ok := r.(error); ok {
+		if errors.Is(err, context.Canceled) {
+			// The debugger was told to quit.
+			return
+		}
+
+		fmt.Fprintf(os.Stderr, "Script returned an error: %v", err)
+		os.Exit(3)
+	}
+}
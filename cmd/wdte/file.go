@@ -9,15 +9,22 @@ import (
 	"github.com/DeedleFake/wdte/std"
 )
 
-func file(im wdte.Importer, file io.Reader) {
-	m, err := wdte.Parse(file, im, nil)
+func file(im wdte.Importer, file io.Reader, cacheDir string, prof *profiler) {
+	src, err := io.ReadAll(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read script: %v", err)
+		os.Exit(1)
+	}
+
+	p, err := compile(src, cacheDir)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to parse script: %v", err)
 		os.Exit(1)
 	}
 
-	ret := m.Call(std.F())
-	if err, ok := ret.(error); ok {
+	_, err = p.Instantiate(prof.frame(std.F().WithImporter(im)))
+	prof.write()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Script returned an error: %v", err)
 		os.Exit(3)
 	}
@@ -1,67 +1,115 @@
 package main
 
 import (
-	"fmt"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/DeedleFake/wdte"
+	"github.com/DeedleFake/wdte/importer"
 	"github.com/DeedleFake/wdte/scanner"
 	"github.com/DeedleFake/wdte/std"
 	_ "github.com/DeedleFake/wdte/std/all"
 )
 
-func importScript(from string, im wdte.Importer, macros scanner.MacroMap) (*wdte.Scope, error) {
-	file, err := os.Open(from + ".wdte")
+// rootDir returns the root of the filesystem containing the
+// directory wd, as well as the slash-separated path of wd inside of
+// it.
+func rootDir(wd string) (string, string, error) {
+	wd, err := filepath.Abs(wd)
 	if err != nil {
-		return nil, err
+		return "", "", err
 	}
-	defer file.Close()
 
-	c, err := wdte.Parse(file, im, macros)
-	if err != nil {
-		return nil, err
+	root := filepath.VolumeName(wd) + string(filepath.Separator)
+	dir := filepath.ToSlash(strings.TrimPrefix(wd, root))
+	if dir == "" {
+		dir = "."
 	}
 
-	s, _ := c.Collect(wdte.F().WithScope(std.Scope))
-	return s, nil
+	return root, dir, nil
+}
+
+// isPath returns true if from should be imported from the filesystem
+// rather than from the standard library or WDTE_PATH.
+func isPath(from string) bool {
+	return strings.HasPrefix(from, "./") || strings.HasPrefix(from, "../") || strings.HasPrefix(from, "/")
+}
+
+// pathImporter returns an importer that imports paths relative to dir
+// from the filesystem, trying a plugin first and then a script, which
+// is imported using files.
+func pathImporter(dir string, plugins *importer.Plugins, files wdte.Importer) wdte.Importer {
+	return wdte.ImportFunc(func(from string) (*wdte.Scope, error) {
+		if !isPath(from) {
+			return nil, os.ErrNotExist
+		}
+
+		s, err := plugins.Import(filepath.ToSlash(filepath.Join(dir, filepath.FromSlash(from))))
+		if !errors.Is(err, os.ErrNotExist) {
+			return s, err
+		}
+
+		return files.Import(from)
+	})
 }
 
-func importer(wd string, blacklist []string, args []string, macros scanner.MacroMap) wdte.Importer {
+func newImporter(wd string, blacklist []string, sandbox bool, args []string, macros scanner.MacroMap) (wdte.Importer, error) {
 	wargs := make(wdte.Array, 0, len(args))
 	for _, arg := range args {
 		wargs = append(wargs, wdte.String(arg))
 	}
 
-	cliScope := wdte.S().Map(map[wdte.ID]wdte.Func{
-		"args": wargs,
-	})
-
-	std.Register("cli", cliScope)
+	cli := importer.Modules{
+		"cli": wdte.S().Map(map[wdte.ID]wdte.Func{
+			"args": wargs,
+		}),
+	}
 
-	return wdte.ImportFunc(func(from string) (*wdte.Scope, error) {
-		for _, m := range blacklist {
-			if from == m {
-				return nil, fmt.Errorf("%q is blacklisted", from)
-			}
+	if sandbox {
+		root, err := filepath.Abs(wd)
+		if err != nil {
+			return nil, err
 		}
 
-		if strings.HasPrefix(from, ".") || strings.HasPrefix(from, "/") {
-			path := filepath.FromSlash(from)
-			im := importer(filepath.Dir(path), blacklist, args, macros)
+		return importer.Deny(importer.Chain{cli, importer.Sandbox(root)}, blacklist...), nil
+	}
 
-			s, err := importPlugin(filepath.Join(wd, path), im)
-			if !os.IsNotExist(err) {
-				return s, err
-			}
+	root, dir, err := rootDir(wd)
+	if err != nil {
+		return nil, err
+	}
+
+	var chain importer.Chain
+	im := importer.Deny(&chain, blacklist...)
+	plugins := &importer.Plugins{Importer: im}
+	dirs := importer.Dirs(im, importer.EnvPath()...)
 
-			s, err = importScript(filepath.Join(wd, path), im, macros)
-			if !os.IsNotExist(err) {
-				return s, err
-			}
+	// local returns the chain of importers used by scripts in the
+	// directory dir, relative to root. Paths are imported relative to
+	// dir, with scripts being imported using files, and everything
+	// else is imported the same way regardless of where the importing
+	// script is.
+	local := func(dir string, files wdte.Importer) importer.Chain {
+		return importer.Chain{
+			cli,
+			pathImporter(filepath.Join(root, filepath.FromSlash(dir)), plugins, files),
+			std.Import,
+			dirs,
 		}
+	}
 
-		return std.Import(from)
-	})
+	files := &importer.FS{
+		FS:     os.DirFS(root),
+		Dir:    dir,
+		Parent: im,
+		Macros: macros,
+		Wrap: func(dir string, files wdte.Importer) wdte.Importer {
+			return importer.Deny(local(dir, files), blacklist...)
+		},
+	}
+	chain = local(dir, files)
+
+	return im, nil
 }
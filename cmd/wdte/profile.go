@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/DeedleFake/wdte"
+	"github.com/DeedleFake/wdte/profile"
+)
+
+// profiler profiles scripts run by the interpreter if a profile was
+// requested with the -cpuprofile flag.
+type profiler struct {
+	path string
+	p    *profile.Profiler
+}
+
+func newProfiler(path, script string) *profiler {
+	if path == "" {
+		return nil
+	}
+
+	return &profiler{
+		path: path,
+		p:    &profile.Profiler{Filename: script},
+	}
+}
+
+// frame attaches the profiler to frame. If p is nil, frame is
+// returned unchanged.
+func (p *profiler) frame(frame wdte.Frame) wdte.Frame {
+	if p == nil {
+		return frame
+	}
+	return frame.WithTracer(p.p)
+}
+
+// write writes the profile to the requested file. If p is nil, it
+// does nothing.
+func (p *profiler) write() {
+	if p == nil {
+		return
+	}
+
+	file, err := os.Create(p.path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create profile: %v\n", err)
+		return
+	}
+	defer file.Close()
+
+	err = p.p.WriteProfile(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write profile: %v\n", err)
+	}
+}
@@ -29,9 +29,9 @@ func printRet(ret wdte.Func) {
 	}
 }
 
-func stdin(im wdte.Importer, macros scanner.MacroMap) {
+func stdin(im wdte.Importer, macros scanner.MacroMap, prof *profiler) {
 	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
-		file(im, os.Stdin)
+		file(im, os.Stdin, "", prof)
 		return
 	}
 
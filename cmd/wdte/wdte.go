@@ -20,11 +20,15 @@ func wdteVersion() (string, error) {
 }
 
 func main() {
-	blacklist := flag.String("blacklist", "", "Comma-separated list of modules that can't be imported.")
+	blacklist := flag.String("blacklist", "", "Comma-separated list of patterns matching modules that can't be imported.")
+	sandbox := flag.Bool("sandbox", false, "Only allow imports of modules that are safe for untrusted scripts, with read-only file access to the current directory.")
 	eval := flag.String("e", "", "An expression to evaluate instead of reading from a file.")
+	cache := flag.String("cache", "", "Directory to cache compiled scripts in. If empty, scripts are not cached.")
+	cpuprofile := flag.String("cpuprofile", "", "Write a pprof profile of the time spent in each WDTE function to the given file.")
 	version := flag.Bool("version", false, "Print the Go and WDTE versions and then exit.")
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %v [options] [<file> | -] [arguments...]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %v [options] [<file> | -] [arguments...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %v [options] debug <file> [arguments...]\n\n", os.Args[0])
 
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
@@ -44,17 +48,51 @@ func main() {
 		return
 	}
 
-	im := importer("", strings.Split(*blacklist, ","), flag.Args(), nil)
+	var deny []string
+	for _, m := range strings.Split(*blacklist, ",") {
+		if m != "" {
+			deny = append(deny, m)
+		}
+	}
+
+	args := flag.Args()
+	debugging := (flag.Arg(0) == "debug") && (flag.NArg() >= 2)
+	if debugging {
+		args = args[1:]
+	}
+
+	if debugging && (*cpuprofile != "") {
+		fmt.Fprintf(os.Stderr, "-cpuprofile can't be used with debug, as the time spent stopped in the debugger would be profiled.\n")
+		os.Exit(2)
+	}
+	if debugging && (*eval != "") {
+		fmt.Fprintf(os.Stderr, "-e can't be used with debug.\n")
+		os.Exit(2)
+	}
+
+	im, err := newImporter("", deny, *sandbox, args, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create importer: %v\n", err)
+		os.Exit(1)
+	}
 
 	if *eval != "" {
-		file(im, strings.NewReader(*eval))
+		file(im, strings.NewReader(*eval), "", newProfiler(*cpuprofile, "<expression>"))
 		return
 	}
 
-	inpath := flag.Arg(0)
+	var inpath string
+	if len(args) > 0 {
+		inpath = args[0]
+	}
 	switch inpath {
 	case "", "-":
-		stdin(im, nil)
+		if debugging {
+			fmt.Fprintf(os.Stderr, "debug requires a file, as the debugger reads its commands from stdin.\n")
+			os.Exit(2)
+		}
+
+		stdin(im, nil, newProfiler(*cpuprofile, "<stdin>"))
 
 	default:
 		f, err := os.Open(inpath)
@@ -64,6 +102,11 @@ func main() {
 		}
 		defer f.Close()
 
-		file(im, f)
+		if debugging {
+			debugScript(im, f, *cache)
+			return
+		}
+
+		file(im, f, *cache, newProfiler(*cpuprofile, inpath))
 	}
 }
@@ -0,0 +1,231 @@
+// wdtegen generates a WDTE module from the exported functions of a Go
+// package. It is intended to be used with go generate. For example,
+// placing
+//
+//    //go:generate go run github.com/DeedleFake/wdte/cmd/wdtegen -register
+//
+// in a package named geo generates a file containing a
+// *wdteutil.Module named Module that contains all of the exported
+// functions in the package under lower camel case names, as well as
+// an init function that registers it with std.Register.
+//
+// Only functions that wdteutil.Func can wrap are included, so generic
+// functions and functions with more than one result other than a
+// trailing error are left out. Because the generated file is built on
+// every platform, functions declared in files with build constraints,
+// either in a //go:build line or in a file name such as
+// dist_windows.go, are left out as well.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// A Function is an exported function found in the package.
+type Function struct {
+	// Name is the name of the function in Go.
+	Name string
+
+	// ID is the name of the function in the module.
+	ID string
+
+	// Doc is the function's doc comment, joined into a single line.
+	Doc string
+}
+
+// Package describes the module to generate.
+type Package struct {
+	Name      string
+	Module    string
+	Var       string
+	Register  bool
+	Functions []Function
+}
+
+// id converts the name of a Go function to lower camel case, such that
+// Distance becomes distance and URLFor becomes urlFor.
+func id(name string) string {
+	r := []rune(name)
+	for i := range r {
+		if !unicode.IsUpper(r[i]) {
+			break
+		}
+		if (i > 0) && (i+1 < len(r)) && unicode.IsLower(r[i+1]) {
+			break
+		}
+		r[i] = unicode.ToLower(r[i])
+	}
+	return string(r)
+}
+
+// supported returns true if wdteutil.Func is able to wrap functions
+// with the type t.
+func supported(t *ast.FuncType) bool {
+	if (t.TypeParams != nil) && (t.TypeParams.NumFields() > 0) {
+		return false
+	}
+
+	if t.Results == nil {
+		return true
+	}
+
+	var n int
+	for _, f := range t.Results.List {
+		if len(f.Names) == 0 {
+			n++
+		}
+		n += len(f.Names)
+	}
+
+	switch n {
+	case 0, 1:
+		return true
+	case 2:
+		last := t.Results.List[len(t.Results.List)-1].Type
+		ident, ok := last.(*ast.Ident)
+		return ok && (ident.Name == "error")
+	}
+	return false
+}
+
+// anyPlatform is a build context that doesn't satisfy any build
+// constraint. A file name that matches it doesn't restrict the file
+// to particular platforms.
+var anyPlatform = build.Context{Compiler: build.Default.Compiler}
+
+// portable returns true if the file name in dir, which has been parsed
+// into file, is built on every platform.
+func portable(dir, name string, file *ast.File) (bool, error) {
+	for _, c := range file.Comments {
+		if c.Pos() > file.Package {
+			break
+		}
+		for _, line := range c.List {
+			if constraint.IsGoBuild(line.Text) || constraint.IsPlusBuild(line.Text) {
+				return false, nil
+			}
+		}
+	}
+
+	return anyPlatform.MatchFile(dir, name)
+}
+
+// load parses the Go files in dir that belong to the package when it
+// is built for the current platform, other than tests and the file at
+// out, and returns the package. Functions are only taken from files
+// that are built on every platform.
+func load(dir, out string, exclude map[string]struct{}) (*Package, error) {
+	bpkg, err := build.Default.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := Package{Name: bpkg.Name}
+	fset := token.NewFileSet()
+	for _, name := range bpkg.GoFiles {
+		if name == filepath.Base(out) {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		ok, err := portable(dir, name, file)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || (fn.Recv != nil) || !fn.Name.IsExported() || !supported(fn.Type) {
+				continue
+			}
+			if _, ok := exclude[fn.Name.Name]; ok {
+				continue
+			}
+
+			pkg.Functions = append(pkg.Functions, Function{
+				Name: fn.Name.Name,
+				ID:   id(fn.Name.Name),
+				Doc:  strings.Join(strings.Fields(fn.Doc.Text()), " "),
+			})
+		}
+	}
+
+	sort.Slice(pkg.Functions, func(i1, i2 int) bool {
+		return pkg.Functions[i1].ID < pkg.Functions[i2].ID
+	})
+	return &pkg, nil
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %v [options] [<dir>]\n", os.Args[0])
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Options:")
+		flag.PrintDefaults()
+	}
+	output := flag.String("out", "wdte_module.go", "File to output to, relative to the package directory.")
+	name := flag.String("name", "", "Name of the module. Defaults to the name of the package.")
+	varName := flag.String("var", "Module", "Name of the generated variable.")
+	register := flag.Bool("register", false, "Generate an init function that registers the module with std.Register.")
+	exclude := flag.String("exclude", "", "Comma-separated list of functions to leave out of the module.")
+	flag.Parse()
+
+	dir := "."
+	switch flag.NArg() {
+	case 0:
+	case 1:
+		dir = flag.Arg(0)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	excluded := make(map[string]struct{})
+	for _, name := range strings.Split(*exclude, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			excluded[name] = struct{}{}
+		}
+	}
+
+	pkg, err := load(dir, *output, excluded)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading package: %v\n", err)
+		os.Exit(1)
+	}
+	pkg.Module = *name
+	if pkg.Module == "" {
+		pkg.Module = pkg.Name
+	}
+	pkg.Var = *varName
+	pkg.Register = *register
+
+	src, err := generate(pkg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating code: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, *output), src, 0644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %q: %v\n", *output, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestID(t *testing.T) {
+	tests := map[string]string{
+		"Distance": "distance",
+		"URLFor":   "urlFor",
+		"URL":      "url",
+		"X":        "x",
+		"HTTPGet":  "httpGet",
+	}
+	for name, expected := range tests {
+		if ret := id(name); ret != expected {
+			t.Errorf("id(%q) = %q, expected %q", name, ret, expected)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	pkg, err := load("testdata/geo", "geo_module.go", map[string]struct{}{"Excluded": {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pkg.Name != "geo" {
+		t.Errorf("Expected package geo, but got %v", pkg.Name)
+	}
+
+	expected := []Function{
+		{Name: "Distance", ID: "distance", Doc: "Distance returns the distance between two points."},
+		{Name: "URLFor", ID: "urlFor", Doc: "URLFor returns a URL for a location."},
+	}
+	if !reflect.DeepEqual(pkg.Functions, expected) {
+		t.Errorf("Unexpected functions:\n%#v\nExpected:\n%#v", pkg.Functions, expected)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	pkg, err := load("testdata/geo", "geo_module.go", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg.Module = "geo"
+	pkg.Var = "Module"
+	pkg.Register = true
+
+	src, err := generate(pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "geo_module.go", src, 0)
+	if err != nil {
+		t.Fatalf("Generated code doesn't parse: %v\n%s", err, src)
+	}
+	if file.Name.Name != "geo" {
+		t.Errorf("Expected package geo, but got %v", file.Name.Name)
+	}
+
+	for _, line := range []string{
+		`Func("distance", Distance, "Distance returns the distance between two points.")`,
+		`Func("excluded", Excluded, "Excluded is excluded with -exclude.")`,
+		`Module.Register(std.Register)`,
+	} {
+		if !strings.Contains(string(src), line) {
+			t.Errorf("Generated code doesn't contain %v:\n%s", line, src)
+		}
+	}
+}
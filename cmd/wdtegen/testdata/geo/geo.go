@@ -0,0 +1,38 @@
+// Package geo is used to test wdtegen.
+package geo
+
+import "errors"
+
+// Distance returns the distance between two points.
+func Distance(x1, y1, x2, y2 float64) float64 {
+	return 0
+}
+
+// URLFor returns a URL
+// for a location.
+func URLFor(name string) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+// Bounds returns more results than can be wrapped.
+func Bounds() (float64, float64) {
+	return 0, 0
+}
+
+// Map is generic.
+func Map[T any](v []T, f func(T) T) []T {
+	return v
+}
+
+// Point is a point.
+type Point struct{}
+
+// Scale is a method.
+func (p Point) Scale(f float64) Point {
+	return p
+}
+
+func unexported() {}
+
+// Excluded is excluded with -exclude.
+func Excluded() {}
@@ -0,0 +1,9 @@
+//go:build !windows
+// +build !windows
+
+package geo
+
+// Native is only declared on some platforms.
+func Native() int {
+	return 0
+}
@@ -0,0 +1,6 @@
+package geo
+
+// Native is only declared on some platforms.
+func Native() int {
+	return 1
+}
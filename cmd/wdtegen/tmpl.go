@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"text/template"
+)
+
+var tmpl = template.Must(template.New("code").Parse(`// Code generated by wdtegen. DO NOT EDIT.
+
+package {{ .Name }}
+
+import (
+	"github.com/DeedleFake/wdte/wdteutil"
+	{{- if .Register }}
+	"github.com/DeedleFake/wdte/std"
+	{{- end }}
+)
+
+// {{ .Var }} is a WDTE module containing the exported functions of
+// this package.
+var {{ .Var }} = wdteutil.NewModule({{ printf "%q" .Module }}){{ range .Functions }}.
+	Func({{ printf "%q" .ID }}, {{ .Name }}, {{ printf "%q" .Doc }}){{ end }}
+{{ if .Register }}
+func init() {
+	{{ .Var }}.Register(std.Register)
+}
+{{ end -}}
+`))
+
+// generate generates the source code of the module described by pkg.
+func generate(pkg *Package) ([]byte, error) {
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,284 @@
+// Package config loads WDTE scripts as configuration files, decoding
+// the values that they produce into Go values.
+//
+// A configuration file is an ordinary script. If it ends with an
+// expression, such as a collector, the value of that expression is
+// the configuration. Otherwise, the scope collected from its
+// top-level let expressions, filtered by wdte.Export, is used. For
+// example, both of the following are equivalent:
+//
+//    let port => 8080;
+//    let hosts => ['a.example.com'; 'b.example.com'];
+//
+//    (|
+//      let port => 8080;
+//      let hosts => ['a.example.com'; 'b.example.com'];
+//    |);
+//
+// Configuration files can import other scripts in the same directory
+// using relative imports, such as import './common', which allows
+// configuration to be split into fragments.
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DeedleFake/wdte"
+	"github.com/DeedleFake/wdte/ast"
+	"github.com/DeedleFake/wdte/importer"
+	"github.com/DeedleFake/wdte/scanner"
+	"github.com/DeedleFake/wdte/std"
+	"github.com/DeedleFake/wdte/wdteutil"
+)
+
+// DefaultTimeout is the time limit used by a Loader with no Timeout
+// set.
+const DefaultTimeout = 5 * time.Second
+
+// DefaultModules are the modules from std that configuration files can
+// import when a Loader has no Importer set. They don't provide access
+// to the host system or to sources of randomness, so evaluating a
+// configuration file with them has no side effects.
+var DefaultModules = []string{
+	"arrays",
+	"math",
+	"stream",
+	"strings",
+}
+
+// A Loader loads configuration files. The zero value is ready to use,
+// and loads files in a restricted environment suitable for
+// configuration that isn't fully trusted.
+type Loader struct {
+	// Scope is the scope that configuration files are evaluated in. If
+	// it is nil, std.Scope is used.
+	Scope *wdte.Scope
+
+	// Importer is used to handle imports that aren't relative. If it is
+	// nil, only the modules in DefaultModules can be imported.
+	Importer wdte.Importer
+
+	// Timeout limits how long loading a file may take, including
+	// evaluating the fragments that it imports and any lazily evaluated
+	// parts of it while decoding. If it is zero, DefaultTimeout is used.
+	// If it is negative, there is no limit.
+	Timeout time.Duration
+
+	// Macros are the macros available to configuration files.
+	Macros scanner.MacroMap
+}
+
+// Load loads the configuration file at the given path in the host
+// filesystem using the default Loader, decoding the result into out.
+func Load(path string, out interface{}) error {
+	var l Loader
+	return l.Load(path, out)
+}
+
+// Load loads the configuration file at the given path in the host
+// filesystem, decoding the result into out. Relative imports in the
+// file are resolved against the directory that it is in, and can't
+// refer to files outside of that directory.
+func (l *Loader) Load(path string, out interface{}) error {
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+
+	err := l.LoadFS(os.DirFS(dir), name, out)
+	var cerr *Error
+	if errors.As(err, &cerr) {
+		cerr.File = path
+	}
+	return err
+}
+
+// LoadFS loads the configuration file with the given name from fsys,
+// decoding the result into out. The result is decoded using
+// wdteutil.Decode, so out and the types that it contains must be
+// supported by it.
+//
+// If the file fails to parse, evaluate, or decode, an *Error is
+// returned. If the problem can be tracked to a specific line of the
+// file, the error includes it. For a value that fails to decode, that
+// is the line that the value is defined on, and for an evaluation
+// error, it is the line of the top-level expression that failed.
+// Problems that happen before evaluation, such as a failed import of
+// a string literal, or that aren't caused by a single expression, such
+// as a missing export, have no line.
+func (l *Loader) LoadFS(fsys fs.FS, name string, out interface{}) error {
+	src, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+
+	wrap := func(line int, err error) error {
+		return &Error{File: name, Line: line, Err: err}
+	}
+
+	// Imported fragments are evaluated while the file is parsed, so
+	// the time limit has to start before that.
+	ctx := context.Background()
+	if timeout := l.timeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	files := &importer.FS{
+		FS:      fsys,
+		Dir:     path.Dir(name),
+		Parent:  l.importer(),
+		Scope:   l.scope(),
+		Macros:  l.Macros,
+		Context: ctx,
+	}
+	im := &fileImporter{files: files, parent: files.Parent}
+
+	c, err := wdte.Parse(bytes.NewReader(src), im, l.Macros)
+	if err != nil {
+		if ctx.Err() != nil {
+			return wrap(0, ctx.Err())
+		}
+
+		var perr ast.ParseError
+		if errors.As(err, &perr) {
+			return wrap(perr.Line, perr.Err)
+		}
+		return wrap(0, err)
+	}
+
+	frame := wdte.F().WithScope(l.scope()).WithImporter(im).WithContext(ctx)
+	r, expr, line, err := evaluate(frame, c)
+	if err != nil {
+		if cerr, ok := frame.Cancelled(); ok {
+			err = cerr
+		}
+		return wrap(line, err)
+	}
+
+	err = wdteutil.Decode(frame, r, out)
+	if cerr, ok := frame.Cancelled(); ok {
+		return wrap(0, cerr)
+	}
+	if err != nil {
+		var derr *wdteutil.DecodeError
+		if errors.As(err, &derr) {
+			return wrap(locate(c, expr, derr.Path), err)
+		}
+		return wrap(0, err)
+	}
+
+	return nil
+}
+
+// evaluate evaluates the top-level compound of a configuration file,
+// returning the configuration that it produces and whether or not
+// that configuration is the value of a final expression rather than
+// the file's collected scope. If evaluation fails, line is the line
+// of the top-level expression that failed, if known.
+func evaluate(frame wdte.Frame, c wdte.Compound) (r wdte.Func, expr bool, line int, err error) {
+	// This is c.Collect(frame), but keeps track of which expression
+	// the result came from.
+	var s *wdte.Scope
+	var last wdte.Func
+	for _, f := range c {
+		switch f := f.(type) {
+		case wdte.Assigner:
+			s, last = f.Assign(frame, s, last)
+		default:
+			last = f.Call(frame.WithScope(frame.Scope().Sub(s)))
+		}
+		line = wdte.PosOf(f).Line
+
+		if _, ok := last.(error); ok && (s == nil) {
+			break
+		}
+	}
+	if err, ok := last.(error); ok {
+		return nil, false, line, err
+	}
+
+	if len(c) > 0 {
+		if _, ok := c[len(c)-1].(wdte.Assigner); !ok {
+			return last, true, 0, nil
+		}
+	}
+
+	if s == nil {
+		s = wdte.S()
+	}
+	s, err = wdte.Export(frame, s)
+	if err != nil {
+		return nil, false, 0, err
+	}
+	return s, false, 0, nil
+}
+
+func (l *Loader) scope() *wdte.Scope {
+	if l.Scope == nil {
+		return std.Scope
+	}
+	return l.Scope
+}
+
+func (l *Loader) importer() wdte.Importer {
+	if l.Importer == nil {
+		return importer.Allow(std.Import, DefaultModules...)
+	}
+	return l.Importer
+}
+
+func (l *Loader) timeout() time.Duration {
+	if l.Timeout == 0 {
+		return DefaultTimeout
+	}
+	return l.Timeout
+}
+
+// fileImporter is the importer used by the file being loaded. It
+// loads relative imports as sibling files and passes all other
+// imports on to parent.
+type fileImporter struct {
+	files  *importer.FS
+	parent wdte.Importer
+}
+
+func (im *fileImporter) Import(from string) (*wdte.Scope, error) {
+	if strings.HasPrefix(from, "./") || strings.HasPrefix(from, "../") {
+		return im.files.Import(from)
+	}
+	return im.parent.Import(from)
+}
+
+// An Error is returned when a configuration file can't be loaded.
+type Error struct {
+	// File is the path of the file that failed to load.
+	File string
+
+	// Line is the line of the file that the problem was found on, or 0
+	// if it isn't known.
+	Line int
+
+	Err error
+}
+
+func (err *Error) Error() string {
+	if err.Line <= 0 {
+		return fmt.Sprintf("%v: %v", err.File, err.Err)
+	}
+	return fmt.Sprintf("%v:%v: %v", err.File, err.Line, err.Err)
+}
+
+func (err *Error) Unwrap() error {
+	return err.Err
+}
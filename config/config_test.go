@@ -0,0 +1,228 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DeedleFake/wdte/config"
+	"github.com/DeedleFake/wdte/importer"
+)
+
+type server struct {
+	Host string `wdte:"host"`
+	Port uint16 `wdte:"port"`
+}
+
+type testConfig struct {
+	Name    string   `wdte:"name"`
+	Servers []server `wdte:"servers"`
+	Tags    []string `wdte:"tags"`
+}
+
+func TestLoad(t *testing.T) {
+	var c testConfig
+	err := config.Load(filepath.Join("testdata", "app.wdte"), &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := testConfig{
+		Name: "app",
+		Servers: []server{
+			{"a.example.com", 8080},
+			{"b.example.com", 8081},
+		},
+		Tags: []string{"APP", "PROD"},
+	}
+	if !reflect.DeepEqual(c, expected) {
+		t.Errorf("Loaded\n\t%#v\nExpected\n\t%#v", c, expected)
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"common.wdte": &fstest.MapFile{Data: []byte(`let port => 80;`)},
+		"scope.wdte": &fstest.MapFile{Data: []byte(`
+let common => import './common';
+let name => 'scope';
+let servers => [(| let host => 'a'; let port => common.port |)];
+`)},
+		"expr.wdte": &fstest.MapFile{Data: []byte(`
+let common => import './common';
+(|
+	let name => 'expr';
+	let servers => [(| let host => 'b'; let port => common.port |)];
+|);
+`)},
+		"module.wdte": &fstest.MapFile{Data: []byte(`
+let module => (| let exports => ['name'] |);
+let name => 'module';
+let servers => 3;
+`)},
+		"type.wdte": &fstest.MapFile{Data: []byte(`let name => 'type';
+let servers => [
+	(| let host => 'a'; let port => 1 |);
+	(|
+		let host => 'b';
+		let port => 'two';
+	|);
+];
+`)},
+		"exprtype.wdte": &fstest.MapFile{Data: []byte(`(|
+	let name => 3;
+|);
+`)},
+		"computed.wdte": &fstest.MapFile{Data: []byte(`let a => import 'arrays'; let s => import 'stream';
+
+let servers => [1; 2] -> a.stream -> s.map (@ f n => n) -> s.collect;
+`)},
+		"syntax.wdte": &fstest.MapFile{Data: []byte(`let name => 'syntax';
+let servers => [;
+`)},
+		"overflow.wdte": &fstest.MapFile{Data: []byte(`let name => 'overflow';
+let servers => [
+	(| let host => 'a'; let port => 70000 |);
+];
+`)},
+		"eval.wdte": &fstest.MapFile{Data: []byte(`let name => 'eval';
+
+missing;
+`)},
+		"denied.wdte":  &fstest.MapFile{Data: []byte(`let io => import 'io'; let name => 'denied';`)},
+		"escape.wdte":  &fstest.MapFile{Data: []byte(`let x => import '../secret'; let name => 'escape';`)},
+		"timeout.wdte": &fstest.MapFile{Data: []byte(`let s => import 'stream'; let name => s.new 0 (+ 1) -> s.collect;`)},
+		"loop.wdte":    &fstest.MapFile{Data: []byte(`let s => import 'stream'; let loop => s.new 0 (+ 1) -> s.drain;`)},
+		"importtimeout.wdte": &fstest.MapFile{Data: []byte(`let loop => import './loop';
+let name => 'importtimeout';
+`)},
+	}
+
+	tests := []struct {
+		name     string
+		file     string
+		loader   config.Loader
+		expected testConfig
+		err      string
+		line     int
+	}{
+		{
+			name:     "Scope",
+			file:     "scope.wdte",
+			expected: testConfig{Name: "scope", Servers: []server{{"a", 80}}},
+		},
+		{
+			name:     "Expr",
+			file:     "expr.wdte",
+			expected: testConfig{Name: "expr", Servers: []server{{"b", 80}}},
+		},
+		{
+			name:     "Module",
+			file:     "module.wdte",
+			expected: testConfig{Name: "module"},
+		},
+		{
+			name: "Type",
+			file: "type.wdte",
+			err:  "type.wdte:6: servers[1].port: expected Number, but got String",
+			line: 6,
+		},
+		{
+			name: "ExprType",
+			file: "exprtype.wdte",
+			err:  "exprtype.wdte:2: name: expected String, but got Number",
+			line: 2,
+		},
+		{
+			name: "Computed",
+			file: "computed.wdte",
+			err:  "computed.wdte:3: servers[0]: ",
+			line: 3,
+		},
+		{
+			name: "Overflow",
+			file: "overflow.wdte",
+			err:  "overflow.wdte:3: servers[0].port: 70000 overflows uint16",
+			line: 3,
+		},
+		{
+			name: "Syntax",
+			file: "syntax.wdte",
+			err:  "syntax.wdte:3:",
+			line: 3,
+		},
+		{
+			name: "Eval",
+			file: "eval.wdte",
+			err:  `eval.wdte:3: "missing" is not in scope`,
+			line: 3,
+		},
+		{
+			name: "Denied",
+			file: "denied.wdte",
+			err:  `import of "io" is not allowed`,
+		},
+		{
+			name:   "Importer",
+			file:   "denied.wdte",
+			loader: config.Loader{Importer: importer.Sandbox("")},
+			expected: testConfig{
+				Name: "denied",
+			},
+		},
+		{
+			name: "Escape",
+			file: "escape.wdte",
+			err:  "outside of the filesystem",
+		},
+		{
+			name:   "Timeout",
+			file:   "timeout.wdte",
+			loader: config.Loader{Timeout: 10 * time.Millisecond},
+			err:    context.DeadlineExceeded.Error(),
+			line:   1,
+		},
+		{
+			name:   "Timeout/Import",
+			file:   "importtimeout.wdte",
+			loader: config.Loader{Timeout: 10 * time.Millisecond},
+			err:    context.DeadlineExceeded.Error(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var c testConfig
+			err := test.loader.LoadFS(fsys, test.file, &c)
+			if test.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(c, test.expected) {
+					t.Errorf("Loaded\n\t%#v\nExpected\n\t%#v", c, test.expected)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("Expected error containing %q, but got nil", test.err)
+			}
+			if !strings.Contains(err.Error(), test.err) {
+				t.Errorf("Expected error containing %q, but got %q", test.err, err)
+			}
+
+			var cerr *config.Error
+			if !errors.As(err, &cerr) {
+				t.Fatalf("Expected *config.Error, but got %#v", err)
+			}
+			if cerr.Line != test.line {
+				t.Errorf("Expected line %v, but got %v", test.line, cerr.Line)
+			}
+		})
+	}
+}
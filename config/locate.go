@@ -0,0 +1,111 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/DeedleFake/wdte"
+)
+
+// literal returns the value of expr if it is a collector or an array
+// written directly in the file, or nil otherwise.
+func literal(expr wdte.Func) wdte.Func {
+	call, ok := expr.(*wdte.FuncCall)
+	if !ok || (len(call.Args) != 0) {
+		return nil
+	}
+
+	switch f := call.Func.(type) {
+	case wdte.Collector:
+		return f.Compound
+	case wdte.Array:
+		return f
+	}
+	return nil
+}
+
+// let returns the last let expression in c that assigns to name, or
+// nil if there isn't one.
+func let(c wdte.Compound, name string) *wdte.LetAssigner {
+	for i := len(c) - 1; i >= 0; i-- {
+		let, ok := c[i].(*wdte.LetAssigner)
+		if ok && (let.Assigner == wdte.SimpleAssigner(name)) {
+			return let
+		}
+	}
+	return nil
+}
+
+// locate makes a best-effort attempt to find the line of the file
+// that c was parsed from that defines the value at path, a path in the
+// format used by wdteutil.DecodeError. expr indicates whether the
+// configuration is the value of the final expression in c, rather than
+// its collected scope. If the value isn't written literally in the
+// file, the line of the closest enclosing value that is is returned
+// instead, or 0 if there isn't one.
+func locate(c wdte.Compound, expr bool, path string) (line int) {
+	cur := wdte.Func(c)
+	if expr {
+		cur = literal(c[len(c)-1])
+	}
+
+	for _, seg := range splitPath(path) {
+		switch seg := seg.(type) {
+		case string:
+			c, ok := cur.(wdte.Compound)
+			if !ok {
+				return line
+			}
+			let := let(c, seg)
+			if let == nil {
+				return line
+			}
+			line = let.Pos.Line
+			cur = literal(let.Expr)
+
+		case int:
+			a, ok := cur.(wdte.Array)
+			if !ok || (seg >= len(a)) {
+				return line
+			}
+			line = wdte.PosOf(a[seg]).Line
+			cur = literal(a[seg])
+		}
+	}
+
+	return line
+}
+
+// splitPath splits a path such as servers[2].port into its
+// components, which are either strings, for names, or ints, for
+// indices.
+func splitPath(path string) (segs []interface{}) {
+	for path != "" {
+		switch {
+		case path[0] == '.':
+			path = path[1:]
+
+		case path[0] == '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return segs
+			}
+			n, err := strconv.Atoi(path[1:end])
+			if err != nil {
+				return segs
+			}
+			segs = append(segs, n)
+			path = path[end+1:]
+
+		default:
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			segs = append(segs, path[:end])
+			path = path[end:]
+		}
+	}
+
+	return segs
+}
@@ -0,0 +1,402 @@
+// Package debugger provides an interactive debugger for WDTE scripts.
+//
+// The debugger reads commands from an io.Reader and writes its output
+// to an io.Writer, so it can be driven either by a user at a terminal
+// or by a script of commands. It stops before function calls, at
+// which point the following commands are available:
+//
+//    break <function | line>  Set a breakpoint. With no argument, list breakpoints.
+//    delete <n>               Delete breakpoint n.
+//    continue                 Run until the next breakpoint.
+//    step                     Run until the next function call.
+//    next                     Run until the next call that isn't inside of the current one.
+//    finish                   Run until the current function returns.
+//    locals                   Print the variables in scope that the script declared.
+//    print <variable>         Print the value of a variable.
+//    backtrace                Print a backtrace.
+//    quit                     Stop the script and exit.
+//    help                     Print a list of commands.
+//
+// Most commands can be abbreviated to their first letter, and
+// backtrace can be abbreviated to bt. An empty line repeats the
+// previous command.
+//
+// Calls are made both to lambdas, including functions declared with
+// let, and to Go functions. Each piece of a chain is also a call, so
+// stepping through a chain stops at each of its pieces. Functions are
+// named as described by wdte.TracedCall.Name, and a breakpoint set on
+// a line stops at every call made from that line.
+//
+// The script being debugged may evaluate functions on several
+// goroutines, such as with the sync module. While the debugger is
+// stopped, every goroutine stops as soon as it calls a function or
+// returns from one. Stepping only stops at calls made by the call
+// that the debugger is stopped at or by its callers, so it won't jump
+// to a call being made concurrently elsewhere, though breakpoints can
+// be hit by any goroutine.
+package debugger
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/DeedleFake/wdte"
+)
+
+// mode determines when the debugger next stops.
+type mode int
+
+const (
+	modeContinue mode = iota
+	modeStep
+	modeNext
+	modeFinish
+	modeQuit
+)
+
+// A Debugger debugs a single script. It is a wdte.Tracer, but
+// should be used via Run rather than by attaching it to a frame
+// manually.
+type Debugger struct {
+	// m is held while handling calls, including while stopped, so
+	// that other goroutines wait until the debugger resumes.
+	m sync.Mutex
+
+	in  *bufio.Scanner
+	out io.Writer
+
+	cancel context.CancelFunc
+	base   map[wdte.ID]struct{}
+
+	breakpoints []breakpoint
+	nextBP      int
+
+	mode mode
+	last string
+
+	// cur is the call that the debugger last stopped at, or nil if it
+	// hasn't stopped at one yet.
+	cur *wdte.TracedCall
+}
+
+// A breakpoint stops at calls to a function, or, if line is non-zero,
+// at calls made from a line.
+type breakpoint struct {
+	n    int
+	name wdte.ID
+	line int
+}
+
+func (bp breakpoint) matches(c *wdte.TracedCall) bool {
+	if bp.line != 0 {
+		return c.Pos.Line == bp.line
+	}
+	return c.Name == bp.name
+}
+
+// New returns a debugger that reads commands from r and writes output
+// to w.
+func New(r io.Reader, w io.Writer) *Debugger {
+	return &Debugger{
+		in:  bufio.NewScanner(r),
+		out: w,
+	}
+}
+
+// Run calls f with frame under the control of the debugger and
+// returns the result. Before evaluation starts, the debugger prompts
+// for commands so that breakpoints can be set. If the debugger is
+// told to quit, evaluation is stopped by cancelling the frame's
+// context, and the resulting error is returned.
+func (d *Debugger) Run(frame wdte.Frame, f wdte.Func) wdte.Func {
+	ctx, cancel := context.WithCancel(frame.Context())
+	defer cancel()
+	d.cancel = cancel
+
+	d.base = make(map[wdte.ID]struct{})
+	for _, id := range frame.Scope().Known() {
+		d.base[id] = struct{}{}
+	}
+
+	fmt.Fprintln(d.out, "Type help for a list of commands.")
+	d.prompt(frame)
+	if d.mode == modeQuit {
+		return wdte.Error{Err: context.Canceled, Frame: frame}
+	}
+
+	r := f.Call(frame.WithContext(ctx).WithTracer(d))
+
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	if d.mode != modeQuit {
+		fmt.Fprintf(d.out, "Script returned %v\n", r)
+	}
+	return r
+}
+
+// within returns true if c is either nil, anc, or one of anc's
+// parents.
+func within(c, anc *wdte.TracedCall) bool {
+	for ; anc != nil; anc = anc.Parent {
+		if c == anc {
+			return true
+		}
+	}
+	return c == nil
+}
+
+func (d *Debugger) Enter(frame wdte.Frame, f wdte.Func, args []wdte.Func) {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	if d.mode == modeQuit {
+		return
+	}
+
+	c := frame.TracedCall()
+	bp, hit := d.breakpoint(c)
+
+	switch {
+	case hit:
+		fmt.Fprintf(d.out, "Breakpoint %v: ", bp.n)
+	case (d.mode == modeStep) && within(c.Parent, d.cur):
+	case (d.mode == modeNext) && ((d.cur == nil) || within(c.Parent, d.cur.Parent)):
+	default:
+		return
+	}
+
+	fmt.Fprintf(d.out, "%v", c.Name)
+	for _, arg := range args {
+		fmt.Fprintf(d.out, " %v", arg)
+	}
+	if c.Pos.Line > 0 {
+		fmt.Fprintf(d.out, " (line %v)", c.Pos.Line)
+	}
+	fmt.Fprintln(d.out)
+
+	d.cur = c
+	d.prompt(frame)
+}
+
+func (d *Debugger) Exit(frame wdte.Frame, f wdte.Func, args []wdte.Func, r wdte.Func) {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	c := frame.TracedCall()
+	if (d.mode != modeFinish) || (c != d.cur) {
+		return
+	}
+
+	fmt.Fprintf(d.out, "%v returned %v\n", c.Name, r)
+	d.cur = c.Parent
+	d.prompt(frame)
+}
+
+func (d *Debugger) Lookup(frame wdte.Frame, id wdte.ID, f wdte.Func) {}
+
+func (d *Debugger) Error(frame wdte.Frame, err error) {}
+
+func (d *Debugger) breakpoint(c *wdte.TracedCall) (breakpoint, bool) {
+	for _, bp := range d.breakpoints {
+		if bp.matches(c) {
+			return bp, true
+		}
+	}
+	return breakpoint{}, false
+}
+
+// prompt reads and runs commands until one of them resumes
+// evaluation. Reaching the end of the input is treated as quit.
+func (d *Debugger) prompt(frame wdte.Frame) {
+	for {
+		fmt.Fprint(d.out, "(wdte) ")
+		if !d.in.Scan() {
+			fmt.Fprintln(d.out)
+			d.quit()
+			return
+		}
+
+		line := strings.TrimSpace(d.in.Text())
+		if line == "" {
+			line = d.last
+		}
+		d.last = line
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if d.command(frame, fields[0], fields[1:]) {
+			return
+		}
+	}
+}
+
+// command runs a single command, returning true if evaluation should
+// resume.
+func (d *Debugger) command(frame wdte.Frame, cmd string, args []string) bool {
+	switch cmd {
+	case "break", "b":
+		d.setBreakpoint(args)
+
+	case "delete", "d":
+		d.deleteBreakpoint(args)
+
+	case "continue", "c":
+		d.mode = modeContinue
+		return true
+
+	case "step", "s":
+		d.mode = modeStep
+		return true
+
+	case "next", "n":
+		d.mode = modeNext
+		return true
+
+	case "finish", "f":
+		if d.cur == nil {
+			fmt.Fprintln(d.out, "Not in a function.")
+			return false
+		}
+		d.mode = modeFinish
+		return true
+
+	case "locals", "l":
+		d.locals(frame)
+
+	case "print", "p":
+		if len(args) != 1 {
+			fmt.Fprintln(d.out, "Usage: print <variable>")
+			return false
+		}
+
+		v := frame.Scope().Get(wdte.ID(args[0]))
+		if v == nil {
+			fmt.Fprintf(d.out, "%q is not in scope.\n", args[0])
+			return false
+		}
+		fmt.Fprintf(d.out, "%v = %v\n", args[0], v)
+
+	case "backtrace", "bt":
+		frame.Backtrace(d.out)
+
+	case "quit", "q":
+		d.quit()
+		return true
+
+	case "help", "h":
+		fmt.Fprint(d.out, help)
+
+	default:
+		fmt.Fprintf(d.out, "Unknown command %q. Type help for a list of commands.\n", cmd)
+	}
+
+	return false
+}
+
+const help = `Commands:
+  break <function | line>  Set a breakpoint. With no argument, list breakpoints.
+  delete <n>               Delete breakpoint n.
+  continue                 Run until the next breakpoint.
+  step                     Run until the next function call.
+  next                     Run until the next call that isn't inside of the current one.
+  finish                   Run until the current function returns.
+  locals                   Print the variables in scope that the script declared.
+  print <variable>         Print the value of a variable.
+  backtrace                Print a backtrace.
+  quit                     Stop the script and exit.
+  help                     Print this list.
+`
+
+func (d *Debugger) quit() {
+	d.mode = modeQuit
+	if d.cancel != nil {
+		d.cancel()
+	}
+}
+
+func (d *Debugger) setBreakpoint(args []string) {
+	if len(args) == 0 {
+		if len(d.breakpoints) == 0 {
+			fmt.Fprintln(d.out, "No breakpoints.")
+		}
+		for _, bp := range d.breakpoints {
+			d.printBreakpoint(bp)
+		}
+		return
+	}
+
+	bp := breakpoint{name: wdte.ID(args[0])}
+	if line, err := strconv.ParseInt(args[0], 10, 0); err == nil {
+		if line <= 0 {
+			fmt.Fprintf(d.out, "Invalid line %v.\n", line)
+			return
+		}
+		bp = breakpoint{line: int(line)}
+	}
+
+	d.nextBP++
+	bp.n = d.nextBP
+	d.breakpoints = append(d.breakpoints, bp)
+	d.printBreakpoint(bp)
+}
+
+func (d *Debugger) printBreakpoint(bp breakpoint) {
+	if bp.line != 0 {
+		fmt.Fprintf(d.out, "Breakpoint %v at line %v\n", bp.n, bp.line)
+		return
+	}
+	fmt.Fprintf(d.out, "Breakpoint %v at %v\n", bp.n, bp.name)
+}
+
+func (d *Debugger) deleteBreakpoint(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(d.out, "Usage: delete <n>")
+		return
+	}
+
+	n, err := strconv.ParseInt(args[0], 10, 0)
+	if err != nil {
+		fmt.Fprintf(d.out, "Invalid breakpoint %q.\n", args[0])
+		return
+	}
+
+	for i, bp := range d.breakpoints {
+		if bp.n == int(n) {
+			d.breakpoints = append(d.breakpoints[:i], d.breakpoints[i+1:]...)
+			fmt.Fprintf(d.out, "Deleted breakpoint %v.\n", n)
+			return
+		}
+	}
+	fmt.Fprintf(d.out, "No breakpoint %v.\n", n)
+}
+
+// locals prints the variables in the frame's scope other than those
+// that were in the scope that the script was started in.
+func (d *Debugger) locals(frame wdte.Frame) {
+	s := frame.Scope()
+
+	var ids []wdte.ID
+	for _, id := range s.Known() {
+		if _, ok := d.base[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i1, i2 int) bool { return ids[i1] < ids[i2] })
+
+	if len(ids) == 0 {
+		fmt.Fprintln(d.out, "No locals.")
+	}
+	for _, id := range ids {
+		fmt.Fprintf(d.out, "%v = %v\n", id, s.Get(id))
+	}
+}
@@ -0,0 +1,275 @@
+package debugger_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DeedleFake/wdte"
+	"github.com/DeedleFake/wdte/debugger"
+	"github.com/DeedleFake/wdte/std"
+	_ "github.com/DeedleFake/wdte/std/stream"
+)
+
+const script = `let double n => * n 2;
+
+let sum a b =>
+	+ (double a) b
+	;
+
+sum 3 4;
+`
+
+func TestDebugger(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		out   string
+		quits bool
+	}{
+		{
+			name: "BreakByName",
+			in:   "b double\nc\nl\np n\nc\n",
+			out: `Type help for a list of commands.
+(wdte) Breakpoint 1 at double
+(wdte) Breakpoint 1: double 3 (line 4)
+(wdte) double = (@ double n => ...)
+n = 3
+(wdte) n = 3
+(wdte) Script returned 10
+`,
+		},
+		{
+			name: "BreakByLine",
+			in:   "b 4\nb\nc\np n\np a\nc\np a\nc\n",
+			out: `Type help for a list of commands.
+(wdte) Breakpoint 1 at line 4
+(wdte) Breakpoint 1 at line 4
+(wdte) Breakpoint 1: double 3 (line 4)
+(wdte) n = 3
+(wdte) "a" is not in scope.
+(wdte) Breakpoint 1: + 6 4 (line 4)
+(wdte) a = 3
+(wdte) Script returned 10
+`,
+		},
+		{
+			name: "Step",
+			in:   "b sum\nc\ns\n\n\nc\n",
+			out: `Type help for a list of commands.
+(wdte) Breakpoint 1 at sum
+(wdte) Breakpoint 1: sum 3 4 (line 7)
+(wdte) double 3 (line 4)
+(wdte) * 3 2 (line 1)
+(wdte) + 6 4 (line 4)
+(wdte) Script returned 10
+`,
+		},
+		{
+			name: "Next",
+			in:   "b double\nc\nd 1\nn\nn\n",
+			out: `Type help for a list of commands.
+(wdte) Breakpoint 1 at double
+(wdte) Breakpoint 1: double 3 (line 4)
+(wdte) Deleted breakpoint 1.
+(wdte) + 6 4 (line 4)
+(wdte) Script returned 10
+`,
+		},
+		{
+			name: "Finish",
+			in:   "b double\nc\nf\nf\nc\n",
+			out: `Type help for a list of commands.
+(wdte) Breakpoint 1 at double
+(wdte) Breakpoint 1: double 3 (line 4)
+(wdte) double returned 6
+(wdte) sum returned 10
+(wdte) Script returned 10
+`,
+		},
+		{
+			name:  "Quit",
+			in:    "s\nq\n",
+			quits: true,
+			out: `Type help for a list of commands.
+(wdte) sum 3 4 (line 7)
+(wdte) `,
+		},
+		{
+			name:  "EOF",
+			in:    "s\n",
+			quits: true,
+			out: `Type help for a list of commands.
+(wdte) sum 3 4 (line 7)
+(wdte) 
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, err := wdte.Parse(strings.NewReader(script), std.Import, nil)
+			if err != nil {
+				t.Fatalf("Failed to parse script: %v", err)
+			}
+
+			var out bytes.Buffer
+			d := debugger.New(strings.NewReader(test.in), &out)
+			r := d.Run(std.F(), m)
+
+			if out.String() != test.out {
+				t.Errorf("Unexpected output:\n%v\nExpected:\n%v", out.String(), test.out)
+			}
+
+			if test.quits {
+				err, ok := r.(error)
+				if !ok || !errors.Is(err, context.Canceled) {
+					t.Errorf("Expected cancellation, but got %v", r)
+				}
+				return
+			}
+			if r != wdte.Number(10) {
+				t.Errorf("Expected 10, but got %v", r)
+			}
+		})
+	}
+}
+
+func TestDebuggerBacktrace(t *testing.T) {
+	m, err := wdte.Parse(strings.NewReader(script), std.Import, nil)
+	if err != nil {
+		t.Fatalf("Failed to parse script: %v", err)
+	}
+
+	var out bytes.Buffer
+	d := debugger.New(strings.NewReader("b double\nc\nbt\nc\n"), &out)
+	d.Run(std.F(), m)
+
+	i := strings.Index(out.String(), "\tdouble\n")
+	if (i < 0) || !strings.Contains(out.String()[i:], "\tCalled from sum\n") {
+		t.Errorf("Backtrace doesn't contain double called from sum:\n%v", out.String())
+	}
+}
+
+func TestDebuggerChain(t *testing.T) {
+	const script = `let s => import 'stream';
+let inc n => + n 1;
+s.range 2
+-> s.map inc
+-> s.reduce 0 +;
+`
+
+	tests := []struct {
+		name string
+		in   string
+		out  string
+	}{
+		{
+			name: "Step",
+			in:   "s\n\n\n\n\n\nc\n",
+			out: `Type help for a list of commands.
+(wdte) s.range 2 (line 3)
+(wdte) s.map (@ inc n => ...) (line 4)
+(wdte) s.map <stream> (line 4)
+(wdte) s.reduce 0 <go func> (line 5)
+(wdte) s.reduce <stream> (line 5)
+(wdte) stream.Reduce <stream> 0 <go func> (line 5)
+(wdte) Script returned 3
+`,
+		},
+		{
+			name: "Next",
+			in:   "n\n\n\n\n\n\n",
+			out: `Type help for a list of commands.
+(wdte) s.range 2 (line 3)
+(wdte) s.map (@ inc n => ...) (line 4)
+(wdte) s.map <stream> (line 4)
+(wdte) s.reduce 0 <go func> (line 5)
+(wdte) s.reduce <stream> (line 5)
+(wdte) Script returned 3
+`,
+		},
+		{
+			name: "BreakByLine",
+			in:   "b 4\nc\nc\nc\n",
+			out: `Type help for a list of commands.
+(wdte) Breakpoint 1 at line 4
+(wdte) Breakpoint 1: s.map (@ inc n => ...) (line 4)
+(wdte) Breakpoint 1: s.map <stream> (line 4)
+(wdte) Script returned 3
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, err := wdte.Parse(strings.NewReader(script), std.Import, nil)
+			if err != nil {
+				t.Fatalf("Failed to parse script: %v", err)
+			}
+
+			var out bytes.Buffer
+			d := debugger.New(strings.NewReader(test.in), &out)
+			r := d.Run(std.F(), m)
+
+			if out.String() != test.out {
+				t.Errorf("Unexpected output:\n%v\nExpected:\n%v", out.String(), test.out)
+			}
+			if r != wdte.Number(3) {
+				t.Errorf("Expected 3, but got %v", r)
+			}
+		})
+	}
+}
+
+func TestDebuggerConcurrent(t *testing.T) {
+	const script = `let s => import 'stream';
+let inc n => + n 1;
+s.range 10
+-> s.pmap 4 inc
+-> s.reduce 0 +;
+`
+
+	run := func(t *testing.T, in string) string {
+		m, err := wdte.Parse(strings.NewReader(script), std.Import, nil)
+		if err != nil {
+			t.Fatalf("Failed to parse script: %v", err)
+		}
+
+		var out bytes.Buffer
+		d := debugger.New(strings.NewReader(in), &out)
+		r := d.Run(std.F(), m)
+		if r != wdte.Number(55) {
+			t.Errorf("Expected 55, but got %v\n%v", r, &out)
+		}
+		return out.String()
+	}
+
+	t.Run("Break", func(t *testing.T) {
+		out := run(t, "b inc\n"+strings.Repeat("c\n", 11))
+		if n := strings.Count(out, "Breakpoint 1: inc "); n != 10 {
+			t.Errorf("Expected 10 breakpoint hits, but got %v:\n%v", n, out)
+		}
+	})
+
+	t.Run("Finish", func(t *testing.T) {
+		// The calls to inc made on other goroutines while finishing
+		// shouldn't be mistaken for the one that was stopped at.
+		out := run(t, "b inc\nc\nd 1\nf\nc\n")
+
+		var n int
+		i := strings.Index(out, "Breakpoint 1: inc ")
+		if i < 0 {
+			t.Fatalf("Breakpoint wasn't hit:\n%v", out)
+		}
+		if _, err := fmt.Sscanf(out[i:], "Breakpoint 1: inc %d", &n); err != nil {
+			t.Fatalf("Failed to parse breakpoint: %v\n%v", err, out)
+		}
+		if !strings.Contains(out, fmt.Sprintf("(wdte) inc returned %v\n(wdte) Script returned", n+1)) {
+			t.Errorf("Expected inc %v to return %v:\n%v", n, n+1, out)
+		}
+	})
+}
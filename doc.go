@@ -39,9 +39,10 @@
 //
 // Example 2
 //    # Import 'stream' and 'array' and assign them to s and a,
-//    # respectively. Note that an import is a compile-time operation,
-//    # unlike normal functions. As such, it must be passed a string
-//    # literal, not a variable.
+//    # respectively. Note that an import of a string literal is a
+//    # compile-time operation, unlike normal functions. An import of a
+//    # variable, such as import name, is instead done at runtime using
+//    # the importer attached to the frame with Frame.WithImporter.
 //    let s => import 'stream';
 //    let a => import 'arrays';
 //
@@ -148,6 +149,13 @@
 //    they're any other character. There's no difference between
 //    single-quoted and double-quoted strings.
 //
+//    Number literals are floating point by default. Suffixing a
+//    literal with i, n, or r, such as 3i, 3n, or 1.5r, results in a
+//    64-bit integer, an arbitrary-precision integer, or an
+//    arbitrary-precision rational number, respectively. Arithmetic
+//    between differing numeric types promotes to the less exact of
+//    the two, with floating point being the least exact.
+//
 //    There are no boolean literals, but the standard library provides
 //    true and false functions that are essentially the same thing.
 //
@@ -213,4 +221,25 @@
 // required, possibly at the cost of some runtime performance,
 // functions for automatically wrapping Go functions are provided in
 // the wdteutil package.
+//
+// Concurrency
+//
+// The result of parsing a script is immutable, so a single parsed
+// script may be called from any number of goroutines at once, as may
+// the values provided by the standard library, such as the modules
+// registered with std.Import, memoized functions, and random number
+// sources. Go implementations of Func that are intended to be shared
+// should be written with this in mind.
+//
+// Streams are the exception. A stream tracks its position, so a single
+// stream should not be read from multiple goroutines at once without
+// some external synchronization. The stream functions that themselves
+// use multiple goroutines, such as those in the sync module and the
+// parallel stream functions, take care of this internally. Each
+// goroutine calling a parsed script gets its own streams as the script
+// creates them, so this only matters if a stream is passed between
+// goroutines.
+//
+// Cancellation and deadlines can be set for a call by passing a Frame
+// with a context attached via Frame.WithContext.
 package wdte
@@ -0,0 +1,523 @@
+package wdte
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// programMagic is written at the start of every encoded program.
+const programMagic = "WDTEC"
+
+// ProgramFormat is the version of the binary format produced by
+// Program.MarshalBinary. It is incremented whenever the format
+// changes, and programs encoded with a different version can't be
+// decoded.
+const ProgramFormat = 2
+
+// ErrProgramFormat is returned when attempting to decode a program
+// that was encoded with a different version of the format, or that
+// isn't an encoded program at all. A program that fails to decode for
+// this reason should be recompiled from its source.
+var ErrProgramFormat = errors.New("unsupported program format")
+
+// Tags identifying the types of encoded nodes. The values of these
+// are part of the format, so existing ones must not be changed
+// without incrementing ProgramFormat.
+const (
+	tagNil byte = iota
+	tagCompound
+	tagCollector
+	tagFuncCall
+	tagChain
+	tagSwitch
+	tagVar
+	tagSub
+	tagArray
+	tagImport
+	tagLambda
+	tagLetAssigner
+	tagSimpleAssigner
+	tagPatternAssigner
+	tagComposite
+	tagModifier
+	tagNumber
+	tagInt
+	tagBigInt
+	tagBigRat
+	tagString
+)
+
+// MarshalBinary encodes the program in a compact binary format that
+// can be decoded with UnmarshalBinary without parsing the original
+// script again. The format is versioned by ProgramFormat.
+func (p *Program) MarshalBinary() ([]byte, error) {
+	e := encoder{buf: bytes.NewBufferString(programMagic)}
+	e.uvarint(ProgramFormat)
+	if err := e.node(p.c); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a program encoded by MarshalBinary into p.
+// If data was encoded with a different version of the format, an
+// error wrapping ErrProgramFormat is returned.
+func (p *Program) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, []byte(programMagic)) {
+		return fmt.Errorf("%w: bad header", ErrProgramFormat)
+	}
+
+	d := decoder{data: data[len(programMagic):]}
+	if v := d.uvarint(); v != ProgramFormat {
+		return fmt.Errorf("%w: version %v, expected %v", ErrProgramFormat, v, ProgramFormat)
+	}
+
+	f := d.node()
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.data) != 0 {
+		return errors.New("trailing data after program")
+	}
+
+	c, ok := f.(Compound)
+	if !ok {
+		return fmt.Errorf("encoded program is a %T, not a Compound", f)
+	}
+
+	p.c = c
+	return nil
+}
+
+type encoder struct {
+	buf *bytes.Buffer
+}
+
+func (e *encoder) uvarint(v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	e.buf.Write(buf[:binary.PutUvarint(buf[:], v)])
+}
+
+func (e *encoder) varint(v int64) {
+	var buf [binary.MaxVarintLen64]byte
+	e.buf.Write(buf[:binary.PutVarint(buf[:], v)])
+}
+
+func (e *encoder) string(s string) {
+	e.uvarint(uint64(len(s)))
+	e.buf.WriteString(s)
+}
+
+func (e *encoder) pos(p Pos) {
+	e.uvarint(uint64(p.Line))
+	e.uvarint(uint64(p.Col))
+}
+
+func (e *encoder) nodes(tag byte, funcs []Func) error {
+	e.buf.WriteByte(tag)
+	e.uvarint(uint64(len(funcs)))
+	for _, f := range funcs {
+		if err := e.node(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *encoder) assigner(a Assigner) error {
+	switch a := a.(type) {
+	case nil:
+		e.buf.WriteByte(tagNil)
+
+	case SimpleAssigner:
+		e.buf.WriteByte(tagSimpleAssigner)
+		e.string(string(a))
+
+	case PatternAssigner:
+		e.buf.WriteByte(tagPatternAssigner)
+		return e.assigners(a)
+
+	default:
+		return fmt.Errorf("can't encode %T", a)
+	}
+
+	return nil
+}
+
+func (e *encoder) assigners(assigners []Assigner) error {
+	e.uvarint(uint64(len(assigners)))
+	for _, a := range assigners {
+		if err := e.assigner(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *encoder) node(f Func) error {
+	switch f := f.(type) {
+	case nil:
+		e.buf.WriteByte(tagNil)
+
+	case Compound:
+		return e.nodes(tagCompound, f)
+
+	case Collector:
+		e.buf.WriteByte(tagCollector)
+		return e.node(f.Compound)
+
+	case *FuncCall:
+		e.buf.WriteByte(tagFuncCall)
+		if err := e.node(f.Func); err != nil {
+			return err
+		}
+		if err := e.nodes(tagArray, f.Args); err != nil {
+			return err
+		}
+		e.pos(f.Pos)
+
+	case Chain:
+		e.buf.WriteByte(tagChain)
+		e.uvarint(uint64(len(f)))
+		for _, p := range f {
+			if err := e.node(p.Expr); err != nil {
+				return err
+			}
+			e.uvarint(uint64(p.Flags))
+			if err := e.assigner(p.Slots); err != nil {
+				return err
+			}
+			e.pos(p.Pos)
+		}
+
+	case *Switch:
+		e.buf.WriteByte(tagSwitch)
+		if err := e.node(f.Check); err != nil {
+			return err
+		}
+		e.uvarint(uint64(len(f.Cases)))
+		for _, c := range f.Cases {
+			if err := e.node(c[0]); err != nil {
+				return err
+			}
+			if err := e.node(c[1]); err != nil {
+				return err
+			}
+		}
+
+	case Var:
+		e.buf.WriteByte(tagVar)
+		e.string(string(f))
+
+	case Sub:
+		return e.nodes(tagSub, f)
+
+	case Array:
+		return e.nodes(tagArray, f)
+
+	case Import:
+		e.buf.WriteByte(tagImport)
+		return e.node(f.Expr)
+
+	case *Lambda:
+		if (f.Scope != nil) || (f.Original != nil) {
+			return errors.New("can't encode a lambda that has been evaluated")
+		}
+
+		e.buf.WriteByte(tagLambda)
+		e.string(string(f.ID))
+		if err := e.node(f.Expr); err != nil {
+			return err
+		}
+		if err := e.assigners(f.Args); err != nil {
+			return err
+		}
+		e.pos(f.Pos)
+
+	case *LetAssigner:
+		e.buf.WriteByte(tagLetAssigner)
+		if err := e.assigner(f.Assigner); err != nil {
+			return err
+		}
+		if err := e.node(f.Expr); err != nil {
+			return err
+		}
+		e.pos(f.Pos)
+
+	case Composite:
+		return e.nodes(tagComposite, f)
+
+	case *Modifier:
+		e.buf.WriteByte(tagModifier)
+		if err := e.node(f.Mods); err != nil {
+			return err
+		}
+		return e.node(f.Func)
+
+	case Number:
+		e.buf.WriteByte(tagNumber)
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(float64(f)))
+		e.buf.Write(buf[:])
+
+	case Int:
+		e.buf.WriteByte(tagInt)
+		e.varint(int64(f))
+
+	case BigInt:
+		e.buf.WriteByte(tagBigInt)
+		e.string(f.Int.String())
+
+	case BigRat:
+		e.buf.WriteByte(tagBigRat)
+		e.string(f.Rat.String())
+
+	case String:
+		e.buf.WriteByte(tagString)
+		e.string(string(f))
+
+	case FuncCall:
+		return e.node(&f)
+	case Switch:
+		return e.node(&f)
+	case LetAssigner:
+		return e.node(&f)
+	case Modifier:
+		return e.node(&f)
+
+	default:
+		return fmt.Errorf("can't encode %T", f)
+	}
+
+	return nil
+}
+
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+	d.data = nil
+}
+
+func (d *decoder) byte() byte {
+	if len(d.data) == 0 {
+		d.fail(errors.New("unexpected end of program"))
+		return tagNil
+	}
+
+	b := d.data[0]
+	d.data = d.data[1:]
+	return b
+}
+
+func (d *decoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.fail(errors.New("malformed integer in program"))
+		return 0
+	}
+
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.fail(errors.New("malformed integer in program"))
+		return 0
+	}
+
+	d.data = d.data[n:]
+	return v
+}
+
+// len reads a length and checks that it is possible for there to be
+// that many remaining elements, each of which takes at least one
+// byte.
+func (d *decoder) len() int {
+	n := d.uvarint()
+	if n > uint64(len(d.data)) {
+		d.fail(errors.New("length in program is too large"))
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) string() string {
+	n := d.len()
+	s := string(d.data[:n])
+	d.data = d.data[n:]
+	return s
+}
+
+func (d *decoder) pos() Pos {
+	return Pos{
+		Line: int(d.uvarint()),
+		Col:  int(d.uvarint()),
+	}
+}
+
+func (d *decoder) nodes() []Func {
+	n := d.len()
+	funcs := make([]Func, 0, n)
+	for i := 0; (i < n) && (d.err == nil); i++ {
+		funcs = append(funcs, d.node())
+	}
+	return funcs
+}
+
+func (d *decoder) assigner() Assigner {
+	if d.err != nil {
+		return nil
+	}
+
+	switch tag := d.byte(); tag {
+	case tagNil:
+		return nil
+
+	case tagSimpleAssigner:
+		return SimpleAssigner(d.string())
+
+	case tagPatternAssigner:
+		return PatternAssigner(d.assigners())
+
+	default:
+		d.fail(fmt.Errorf("expected assigner in program, but got tag %v", tag))
+		return nil
+	}
+}
+
+func (d *decoder) assigners() []Assigner {
+	n := d.len()
+	assigners := make([]Assigner, 0, n)
+	for i := 0; (i < n) && (d.err == nil); i++ {
+		assigners = append(assigners, d.assigner())
+	}
+	return assigners
+}
+
+func (d *decoder) node() Func {
+	if d.err != nil {
+		return nil
+	}
+
+	switch tag := d.byte(); tag {
+	case tagNil:
+		return nil
+
+	case tagCompound:
+		return Compound(d.nodes())
+
+	case tagCollector:
+		c, _ := d.node().(Compound)
+		return Collector{Compound: c}
+
+	case tagFuncCall:
+		f := d.node()
+		args, _ := d.node().(Array)
+		return &FuncCall{Func: f, Args: args, Pos: d.pos()}
+
+	case tagChain:
+		n := d.len()
+		chain := make(Chain, 0, n)
+		for i := 0; (i < n) && (d.err == nil); i++ {
+			chain = append(chain, &ChainPiece{
+				Expr:  d.node(),
+				Flags: uint(d.uvarint()),
+				Slots: d.assigner(),
+				Pos:   d.pos(),
+			})
+		}
+		return chain
+
+	case tagSwitch:
+		check := d.node()
+		n := d.len()
+		cases := make([][2]Func, 0, n)
+		for i := 0; (i < n) && (d.err == nil); i++ {
+			cases = append(cases, [...]Func{d.node(), d.node()})
+		}
+		return &Switch{Check: check, Cases: cases}
+
+	case tagVar:
+		return Var(d.string())
+
+	case tagSub:
+		return Sub(d.nodes())
+
+	case tagArray:
+		return Array(d.nodes())
+
+	case tagImport:
+		return Import{Expr: d.node()}
+
+	case tagLambda:
+		return &Lambda{
+			ID:   ID(d.string()),
+			Expr: d.node(),
+			Args: d.assigners(),
+			Pos:  d.pos(),
+		}
+
+	case tagLetAssigner:
+		return &LetAssigner{
+			Assigner: d.assigner(),
+			Expr:     d.node(),
+			Pos:      d.pos(),
+		}
+
+	case tagComposite:
+		return Composite(d.nodes())
+
+	case tagModifier:
+		return &Modifier{
+			Mods: d.node(),
+			Func: d.node(),
+		}
+
+	case tagNumber:
+		if len(d.data) < 8 {
+			d.fail(errors.New("unexpected end of program"))
+			return nil
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(d.data))
+		d.data = d.data[8:]
+		return Number(v)
+
+	case tagInt:
+		return Int(d.varint())
+
+	case tagBigInt:
+		i, ok := new(big.Int).SetString(d.string(), 10)
+		if !ok {
+			d.fail(errors.New("malformed big integer in program"))
+			return nil
+		}
+		return BigInt{i}
+
+	case tagBigRat:
+		r, ok := new(big.Rat).SetString(d.string())
+		if !ok {
+			d.fail(errors.New("malformed big rational in program"))
+			return nil
+		}
+		return BigRat{r}
+
+	case tagString:
+		return String(d.string())
+
+	default:
+		d.fail(fmt.Errorf("unknown tag %v in program", tag))
+		return nil
+	}
+}
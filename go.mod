@@ -7,4 +7,4 @@ require (
 	golang.org/x/crypto v0.6.0
 )
 
-go 1.13
+go 1.16
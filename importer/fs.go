@@ -0,0 +1,193 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/DeedleFake/wdte"
+	"github.com/DeedleFake/wdte/scanner"
+	"github.com/DeedleFake/wdte/std"
+)
+
+// FS is an importer that loads scripts from an fs.FS. Imported
+// scripts are evaluated and the scope collected from their top-level
+// let expressions, filtered by wdte.Export, is used as the module.
+// Modules are cached, so each script is only loaded once per FS.
+//
+// Module names are slash-separated paths without the script's
+// extension. Names beginning with "./" or "../" are relative to the
+// directory of the script doing the importing, or to Dir if imported
+// directly. Names beginning with "/" are relative to the root of the
+// filesystem, as are all other names. For example, with the default
+// extension, both "/lib/util" and "lib/util" import the file
+// "lib/util.wdte". Names that refer to a location outside of the
+// filesystem are an error.
+//
+// An FS is safe for concurrent use, though concurrent imports of the
+// same module may cause it to be loaded more than once.
+type FS struct {
+	// FS is the filesystem to load scripts from.
+	FS fs.FS
+
+	// Dir is the directory that relative imports are resolved against
+	// when imported directly. If it is empty, the root of FS is used.
+	Dir string
+
+	// Ext is the extension appended to module names to get the names
+	// of the files to load. If it is empty, ".wdte" is used.
+	Ext string
+
+	// Parent is used by loaded scripts to import modules that aren't
+	// relative, such as "stream". If it is nil, the FS itself is used.
+	// Relative imports are always handled by the FS.
+	Parent wdte.Importer
+
+	// Scope is the scope that loaded scripts are evaluated in. If it is
+	// nil, std.Scope is used.
+	Scope *wdte.Scope
+
+	// Macros are the macros available to loaded scripts.
+	Macros scanner.MacroMap
+
+	// Context is the context that loaded scripts are evaluated with,
+	// allowing the loading of a module to be cancelled. If it is nil,
+	// context.Background() is used.
+	Context context.Context
+
+	// Wrap, if not nil, is called to get the importer used by each
+	// loaded script. im is the importer that the script would use
+	// otherwise, which loads relative modules from the FS and passes
+	// all others to Parent, and dir is the script's directory in the
+	// FS. This allows the relative imports of loaded scripts to go
+	// through the same policies and importers, such as Plugins, as
+	// any other import.
+	Wrap func(dir string, im wdte.Importer) wdte.Importer
+
+	m     sync.Mutex
+	cache map[string]*wdte.Scope
+}
+
+func (f *FS) Import(from string) (*wdte.Scope, error) {
+	return f.importStack(from, nil)
+}
+
+func (f *FS) importStack(from string, stack []loading) (*wdte.Scope, error) {
+	p, err := resolve(f.Dir, from)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.load(p, stack)
+}
+
+func isRelative(from string) bool {
+	return strings.HasPrefix(from, "./") || strings.HasPrefix(from, "../")
+}
+
+// resolve converts a module name into a path in the filesystem,
+// relative to dir if the name is relative.
+func resolve(dir, from string) (string, error) {
+	var p string
+	switch {
+	case isRelative(from):
+		p = path.Join(dir, from)
+	default:
+		p = path.Clean(strings.TrimPrefix(from, "/"))
+	}
+
+	if (p == ".") || !fs.ValidPath(p) {
+		return "", fmt.Errorf("import %q is outside of the filesystem", from)
+	}
+	return p, nil
+}
+
+func (f *FS) ext() string {
+	if f.Ext == "" {
+		return ".wdte"
+	}
+	return f.Ext
+}
+
+func (f *FS) load(p string, stack []loading) (*wdte.Scope, error) {
+	cur := loading{fs: f, path: p}
+	if err := checkCycle(stack, cur); err != nil {
+		return nil, err
+	}
+
+	f.m.Lock()
+	s, ok := f.cache[p]
+	f.m.Unlock()
+	if ok {
+		return s, nil
+	}
+
+	file, err := f.FS.Open(p + f.ext())
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stack = append(stack[:len(stack):len(stack)], cur)
+	var im wdte.Importer = &scriptImporter{fs: f, dir: path.Dir(p), stack: stack}
+	if f.Wrap != nil {
+		im = f.Wrap(path.Dir(p), im)
+	}
+	c, err := wdte.Parse(file, im, f.Macros)
+	if err != nil {
+		return nil, &LoadError{Path: p, Err: err}
+	}
+
+	scope := f.Scope
+	if scope == nil {
+		scope = std.Scope
+	}
+
+	frame := wdte.F().WithScope(scope).WithImporter(im)
+	if f.Context != nil {
+		frame = frame.WithContext(f.Context)
+	}
+	s, last := c.Collect(frame)
+	if err, ok := last.(error); ok {
+		return nil, &LoadError{Path: p, Err: err}
+	}
+
+	s, err = wdte.Export(frame, s)
+	if err != nil {
+		return nil, &LoadError{Path: p, Err: err}
+	}
+
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if f.cache == nil {
+		f.cache = make(map[string]*wdte.Scope)
+	}
+	if c, ok := f.cache[p]; ok {
+		return c, nil
+	}
+	f.cache[p] = s
+	return s, nil
+}
+
+// scriptImporter is the importer used by scripts loaded by an FS.
+type scriptImporter struct {
+	fs    *FS
+	dir   string
+	stack []loading
+}
+
+func (im *scriptImporter) Import(from string) (*wdte.Scope, error) {
+	if isRelative(from) || (im.fs.Parent == nil) {
+		p, err := resolve(im.dir, from)
+		if err != nil {
+			return nil, err
+		}
+		return im.fs.load(p, im.stack)
+	}
+
+	return importStack(im.fs.Parent, from, im.stack)
+}
@@ -0,0 +1,179 @@
+// Package importer provides implementations of wdte.Importer for
+// loading modules from filesystems, as well as types for combining
+// importers.
+//
+// Importers in this package signal that a module doesn't exist by
+// returning an error for which errors.Is(err, fs.ErrNotExist) is
+// true. std.Import does the same, allowing it to be combined with the
+// importers in this package using Chain.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DeedleFake/wdte"
+)
+
+// stackImporter is implemented by the importers in this package
+// so that the chain of modules currently being loaded can be passed
+// between them, allowing import cycles to be detected.
+type stackImporter interface {
+	importStack(from string, stack []loading) (*wdte.Scope, error)
+}
+
+// loading identifies a module that is in the process of being loaded,
+// either by an FS or by a Plugins.
+type loading struct {
+	fs      *FS
+	plugins *Plugins
+	path    string
+}
+
+func importStack(im wdte.Importer, from string, stack []loading) (*wdte.Scope, error) {
+	if im, ok := im.(stackImporter); ok {
+		return im.importStack(from, stack)
+	}
+
+	return im.Import(from)
+}
+
+// boundImporter is an importer that imports from im with a fixed
+// stack. It is given to code outside of this package, such as
+// plugins, that imports modules while another is being loaded.
+type boundImporter struct {
+	im    wdte.Importer
+	stack []loading
+}
+
+func (im boundImporter) Import(from string) (*wdte.Scope, error) {
+	return importStack(im.im, from, im.stack)
+}
+
+// checkCycle returns a *CycleError if cur is already in stack.
+func checkCycle(stack []loading, cur loading) error {
+	for i, l := range stack {
+		if l == cur {
+			names := make([]string, 0, len(stack)-i+1)
+			for _, l := range stack[i:] {
+				names = append(names, l.path)
+			}
+			return &CycleError{Path: append(names, cur.path)}
+		}
+	}
+	return nil
+}
+
+// notFound returns an error indicating that from doesn't exist.
+func notFound(from string) error {
+	return &fs.PathError{Op: "import", Path: from, Err: fs.ErrNotExist}
+}
+
+// isNotFound returns true if err indicates that a module doesn't
+// exist, as opposed to having failed to load.
+func isNotFound(err error) bool {
+	var lerr *LoadError
+	return errors.Is(err, fs.ErrNotExist) && !errors.As(err, &lerr)
+}
+
+// A LoadError is returned when a module was found but couldn't be
+// loaded, such as because of a syntax error.
+type LoadError struct {
+	// Path is the path of the module that failed to load.
+	Path string
+	Err  error
+}
+
+func (err *LoadError) Error() string {
+	return fmt.Sprintf("%v: %v", err.Path, err.Err)
+}
+
+func (err *LoadError) Unwrap() error {
+	return err.Err
+}
+
+// Chain is an importer that tries each of its importers in turn,
+// returning the first module found. If an importer returns an error
+// other than one indicating that the module doesn't exist, that error
+// is returned immediately without trying the remaining importers. A
+// LoadError is never considered to indicate that a module doesn't
+// exist, even if it wraps an error that does.
+//
+// Chain can be used to override modules from another importer by
+// placing an importer containing the overrides, such as Modules,
+// earlier in the chain. For example,
+//
+//    importer.Chain{
+//      importer.Modules{"config": config},
+//      std.Import,
+//    }
+type Chain []wdte.Importer
+
+func (c Chain) Import(from string) (*wdte.Scope, error) {
+	return c.importStack(from, nil)
+}
+
+func (c Chain) importStack(from string, stack []loading) (*wdte.Scope, error) {
+	for _, im := range c {
+		s, err := importStack(im, from, stack)
+		if (err == nil) || !isNotFound(err) {
+			return s, err
+		}
+	}
+
+	return nil, notFound(from)
+}
+
+// Modules is an importer that imports modules from a map.
+type Modules map[string]*wdte.Scope
+
+func (m Modules) Import(from string) (*wdte.Scope, error) {
+	s, ok := m[from]
+	if !ok {
+		return nil, notFound(from)
+	}
+	return s, nil
+}
+
+// A CycleError is returned when an import cycle is detected. Path
+// contains the modules involved in the cycle in the order that they
+// were imported, starting and ending with the same module.
+type CycleError struct {
+	Path []string
+}
+
+func (err *CycleError) Error() string {
+	return fmt.Sprintf("import cycle: %v", strings.Join(err.Path, " -> "))
+}
+
+// EnvPath returns the list of directories in the WDTE_PATH
+// environment variable, which is formatted the same way as the
+// system's PATH environment variable.
+func EnvPath() []string {
+	return filepath.SplitList(os.Getenv("WDTE_PATH"))
+}
+
+// Dirs returns an importer that searches each of the given
+// directories in order for scripts. parent is used as the Parent of
+// each of the returned importers. For example, to search the
+// directories in WDTE_PATH after std.Import:
+//
+//    var im importer.Chain
+//    im = importer.Chain{std.Import, importer.Dirs(&im, importer.EnvPath()...)}
+//
+// As shown above, a pointer to a Chain may be used as parent in order
+// to refer to a Chain that includes the returned importer.
+func Dirs(parent wdte.Importer, dirs ...string) Chain {
+	c := make(Chain, 0, len(dirs))
+	for _, dir := range dirs {
+		c = append(c, &FS{
+			FS:     os.DirFS(dir),
+			Parent: parent,
+		})
+	}
+	return c
+}
@@ -0,0 +1,338 @@
+package importer_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DeedleFake/wdte"
+	"github.com/DeedleFake/wdte/importer"
+	"github.com/DeedleFake/wdte/std"
+	_ "github.com/DeedleFake/wdte/std/stream"
+)
+
+var testFS = fstest.MapFS{
+	"lib/a.wdte":      {Data: []byte(`let b => import './b'; let x => + b.y 1;`)},
+	"lib/b.wdte":      {Data: []byte(`let y => 41;`)},
+	"lib/sub/c.wdte":  {Data: []byte(`let a => import '../a'; let z => a.x;`)},
+	"cycle/one.wdte":  {Data: []byte(`let two => import './two';`)},
+	"cycle/two.wdte":  {Data: []byte(`let one => import '/cycle/one';`)},
+	"std.wdte":        {Data: []byte(`let s => import 'stream'; let sum => s.range 4 -> s.reduce 0 +;`)},
+	"escape.wdte":     {Data: []byte(`let x => import '../outside';`)},
+	"broken.wdte":     {Data: []byte(`let x => ;`)},
+	"other/mod.wdte":  {Data: []byte(`let v => 'other';`)},
+	"other/main.wdte": {Data: []byte(`let m => import 'mod'; let v => m.v;`)},
+	"meta.wdte": {Data: []byte(`let module => (|
+	let name => 'adder';
+	let version => '1.0.0';
+	let deps => ['strings'];
+	let exports => ['add'];
+|);
+let base => 40;
+let add n => + base n;`)},
+}
+
+func TestFS(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		id   wdte.ID
+		ret  wdte.Func
+	}{
+		{"Relative", "lib/a", "x", wdte.Number(42)},
+		{"Absolute", "/lib/a", "x", wdte.Number(42)},
+		{"Parent", "lib/sub/c", "z", wdte.Number(42)},
+	}
+
+	im := &importer.FS{FS: testFS}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			s, err := im.Import(test.from)
+			if err != nil {
+				t.Fatalf("Failed to import %q: %v", test.from, err)
+			}
+
+			ret := s.Get(test.id).Call(std.F())
+			if !wdte.Equal(ret, test.ret) {
+				t.Errorf("Expected %v, but got %v", test.ret, ret)
+			}
+		})
+	}
+
+	t.Run("Cache", func(t *testing.T) {
+		s1, err := im.Import("lib/b")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s2, err := im.Import("./lib/b")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if s1 != s2 {
+			t.Errorf("Module was loaded twice")
+		}
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		_, err := im.Import("cycle/one")
+
+		var cerr *importer.CycleError
+		if !errors.As(err, &cerr) {
+			t.Fatalf("Expected a cycle error, but got %v", err)
+		}
+
+		expected := []string{"cycle/one", "cycle/two", "cycle/one"}
+		if len(cerr.Path) != len(expected) {
+			t.Fatalf("Expected cycle %v, but got %v", expected, cerr.Path)
+		}
+		for i := range expected {
+			if cerr.Path[i] != expected[i] {
+				t.Fatalf("Expected cycle %v, but got %v", expected, cerr.Path)
+			}
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := im.Import("missing")
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Expected a not found error, but got %v", err)
+		}
+	})
+
+	t.Run("Escape", func(t *testing.T) {
+		_, err := im.Import("escape")
+		if (err == nil) || errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Expected an error, but got %v", err)
+		}
+	})
+
+	t.Run("Module", func(t *testing.T) {
+		s, err := im.Import("meta")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if s.Get("base") != nil {
+			t.Errorf("Unexported variable is visible")
+		}
+		if s.Get("module") != nil {
+			t.Errorf("Module metadata is visible as a variable")
+		}
+
+		ret := s.Get("add").Call(std.F(), wdte.Number(2))
+		if !wdte.Equal(ret, wdte.Number(42)) {
+			t.Errorf("Expected 42, but got %v", ret)
+		}
+
+		info := s.Module()
+		if info == nil {
+			t.Fatal("Module has no metadata")
+		}
+		if (info.Name != "adder") || (info.Version != "1.0.0") || (len(info.Deps) != 1) || (info.Deps[0] != "strings") {
+			t.Errorf("Unexpected metadata: %+v", info)
+		}
+	})
+
+	t.Run("Broken", func(t *testing.T) {
+		_, err := im.Import("broken")
+		if (err == nil) || errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Expected a parse error, but got %v", err)
+		}
+	})
+
+	t.Run("Wrap", func(t *testing.T) {
+		var dirs []string
+		im := &importer.FS{
+			FS: testFS,
+			Wrap: func(dir string, im wdte.Importer) wdte.Importer {
+				dirs = append(dirs, dir)
+				return importer.Deny(im, "./b")
+			},
+		}
+
+		_, err := im.Import("lib/sub/c")
+		var derr *importer.DeniedError
+		if !errors.As(err, &derr) || (derr.Module != "./b") {
+			t.Fatalf("Expected ./b to be denied, but got %v", err)
+		}
+
+		expected := []string{"lib/sub", "lib"}
+		if strings.Join(dirs, ",") != strings.Join(expected, ",") {
+			t.Errorf("Expected directories %v, but got %v", expected, dirs)
+		}
+	})
+
+	t.Run("Wrap/Cycle", func(t *testing.T) {
+		im := &importer.FS{
+			FS: testFS,
+			Wrap: func(dir string, im wdte.Importer) wdte.Importer {
+				return wdte.ImportFunc(im.Import)
+			},
+		}
+
+		_, err := im.Import("cycle/one")
+		var cerr *importer.CycleError
+		if !errors.As(err, &cerr) {
+			t.Fatalf("Expected a cycle error, but got %v", err)
+		}
+	})
+}
+
+func TestChain(t *testing.T) {
+	var im importer.Chain
+	im = importer.Chain{
+		importer.Modules{"mod": wdte.S().Add("v", wdte.String("override"))},
+		std.Import,
+		&importer.FS{FS: testFS, Parent: &im},
+	}
+
+	t.Run("Std", func(t *testing.T) {
+		s, err := im.Import("std")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ret := s.Get("sum").Call(std.F())
+		if !wdte.Equal(ret, wdte.Number(6)) {
+			t.Errorf("Expected 6, but got %v", ret)
+		}
+	})
+
+	t.Run("Override", func(t *testing.T) {
+		s, err := im.Import("other/main")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ret := s.Get("v").Call(std.F())
+		if !wdte.Equal(ret, wdte.String("override")) {
+			t.Errorf("Expected override, but got %v", ret)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := im.Import("missing")
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Expected a not found error, but got %v", err)
+		}
+	})
+
+	t.Run("LoadError", func(t *testing.T) {
+		_, err := importer.Chain{
+			&importer.FS{FS: fstest.MapFS{
+				"mod.wdte": {Data: []byte(`let m => import 'missing';`)},
+			}},
+			importer.Modules{"mod": wdte.S()},
+		}.Import("mod")
+
+		var lerr *importer.LoadError
+		if !errors.As(err, &lerr) {
+			t.Errorf("Expected a load error, but got %v", err)
+		}
+	})
+}
+
+func TestPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		im      wdte.Importer
+		from    string
+		allowed bool
+	}{
+		{"Allow", importer.Allow(std.Import, "stream", "io/*"), "stream", true},
+		{"Allow/Pattern", importer.Allow(std.Import, "stream", "io/*"), "io/file", true},
+		{"Allow/Denied", importer.Allow(std.Import, "stream", "io/*"), "io", false},
+		{"Deny", importer.Deny(std.Import, "io/*"), "stream", true},
+		{"Deny/Denied", importer.Deny(std.Import, "io/*"), "io/file", false},
+		{"Sandbox", importer.Sandbox(""), "strings", true},
+		{"Sandbox/IO", importer.Sandbox(""), "io", true},
+		{"Sandbox/File", importer.Sandbox(""), "io/file", false},
+		{"Sandbox/Sync", importer.Sandbox(""), "sync", false},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.im.Import(test.from)
+
+			var derr *importer.DeniedError
+			switch {
+			case test.allowed && (err != nil):
+				t.Errorf("Expected %q to be allowed, but got %v", test.from, err)
+			case !test.allowed && !errors.As(err, &derr):
+				t.Errorf("Expected %q to be denied, but got %v", test.from, err)
+			}
+		})
+	}
+
+	t.Run("Sandbox/Stream", func(t *testing.T) {
+		s, err := importer.Sandbox("").Import("stream")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, id := range []wdte.ID{"pmap", "pmapUnordered", "pfilter", "pfilterUnordered"} {
+			if s.Get(id) != nil {
+				t.Errorf("%v is available", id)
+			}
+		}
+		if s.Get("map") == nil {
+			t.Errorf("map is not available")
+		}
+		for _, id := range s.Known() {
+			if id == "pmap" {
+				t.Errorf("pmap is known")
+			}
+		}
+	})
+
+	t.Run("Sandbox/Stdin", func(t *testing.T) {
+		s, err := importer.Sandbox("").Import("io")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if s.Get("stdin") != nil {
+			t.Errorf("stdin is available")
+		}
+		if s.Get("stdout") == nil {
+			t.Errorf("stdout is not available")
+		}
+	})
+
+	t.Run("Sandbox/Root", func(t *testing.T) {
+		root := t.TempDir()
+		err := os.WriteFile(filepath.Join(root, "test.txt"), []byte("sandboxed"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		const script = `let io => import 'io';
+let file => import 'io/file';
+[
+	file.open '/../../test.txt' -> io.string;
+	reflect (file.create 'new.txt') 'Error';
+];`
+
+		m, err := wdte.Parse(strings.NewReader(script), importer.Sandbox(root), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ret := m.Call(std.F())
+		expected := wdte.Array{wdte.String("sandboxed"), wdte.Bool(true)}
+		if !wdte.Equal(ret, expected) {
+			t.Errorf("Expected %v, but got %v", expected, ret)
+		}
+
+		if _, err := os.Stat(filepath.Join(root, "new.txt")); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("File was created in read-only sandbox")
+		}
+	})
+}
@@ -0,0 +1,248 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+
+	"github.com/DeedleFake/wdte"
+)
+
+// PluginABI is the version of the interface between plugins and the
+// host program. It is incremented whenever PluginInfo or PluginConfig
+// change in an incompatible way. Plugins record the value that they
+// were built with in PluginInfo.ABI.
+const PluginABI = 1
+
+// PluginSymbol is the name of the variable that a plugin must export
+// in order to be loaded. Its type must be PluginInfo. For example,
+//
+//    package main
+//
+//    var WDTEPlugin = importer.PluginInfo{
+//      ABI: importer.PluginABI,
+//      Init: func(config importer.PluginConfig) (*wdte.Scope, error) {
+//        return wdte.S().Add("x", wdte.Number(3)), nil
+//      },
+//    }
+//
+// The plugin can then be built with
+//
+//    go build -buildmode=plugin
+const PluginSymbol = "WDTEPlugin"
+
+// ErrPluginsUnsupported is returned when attempting to load a plugin
+// on a platform that doesn't support Go plugins.
+var ErrPluginsUnsupported = errors.New("plugins are not supported on this platform")
+
+// PluginInfo describes a plugin. See PluginSymbol.
+type PluginInfo struct {
+	// ABI is the value of PluginABI that the plugin was built with.
+	ABI int
+
+	// Init is called once when the plugin is loaded. The scope that it
+	// returns is used as the module.
+	Init func(config PluginConfig) (*wdte.Scope, error)
+}
+
+// PluginConfig is passed to the Init function of a plugin when it is
+// loaded.
+type PluginConfig struct {
+	// Importer is the host's importer. Plugins that need to import
+	// other modules should use it rather than, for example, std.Import,
+	// so that the host's import policies apply to them.
+	Importer wdte.Importer
+
+	// Path is the path that the plugin was loaded from.
+	Path string
+
+	// Version is the version of WDTE used by the host, as returned by
+	// Version.
+	Version string
+
+	// Options contains host-specific configuration for the plugin.
+	Options map[string]string
+}
+
+// A PluginVersionError is returned when a plugin was built against a
+// version of WDTE that isn't compatible with the host.
+type PluginVersionError struct {
+	// Path is the path of the plugin.
+	Path string
+
+	// ABI is the plugin's ABI version, or zero if it couldn't be
+	// determined.
+	ABI int
+
+	// Version is the version of WDTE used by the host.
+	Version string
+
+	// Err is the underlying error, if any.
+	Err error
+}
+
+func (err *PluginVersionError) Error() string {
+	host := err.Version
+	if host == "" {
+		host = "unknown"
+	}
+
+	if err.Err != nil {
+		return fmt.Sprintf("plugin %v is incompatible with WDTE %v (ABI %v): %v", err.Path, host, PluginABI, err.Err)
+	}
+	return fmt.Sprintf("plugin %v uses ABI %v, but WDTE %v uses ABI %v", err.Path, err.ABI, host, PluginABI)
+}
+
+func (err *PluginVersionError) Unwrap() error {
+	return err.Err
+}
+
+// Version returns the version of WDTE that the running program was
+// built with, as determined by Go's module system. If it can't be
+// determined, such as when the program was built without module
+// support, an empty string is returned.
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	if info.Main.Path == "github.com/DeedleFake/wdte" {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == "github.com/DeedleFake/wdte" {
+			return dep.Version
+		}
+	}
+
+	return ""
+}
+
+// LoadPlugin loads the plugin at path and calls its Init function
+// with config. If config.Path or config.Version are empty, they are
+// filled in automatically. If path doesn't exist, an error for which
+// errors.Is(err, fs.ErrNotExist) is true is returned.
+//
+// Go doesn't allow a plugin to be unloaded, nor does it allow a plugin
+// built against a different version of a package that the host also
+// uses to be loaded. In the latter case, or if the plugin was built
+// with a different value of PluginABI, a *PluginVersionError is
+// returned.
+func LoadPlugin(path string, config PluginConfig) (*wdte.Scope, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	if config.Path == "" {
+		config.Path = path
+	}
+	if config.Version == "" {
+		config.Version = Version()
+	}
+
+	info, err := openPlugin(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.ABI != PluginABI {
+		return nil, &PluginVersionError{Path: path, ABI: info.ABI, Version: config.Version}
+	}
+	if info.Init == nil {
+		return nil, &LoadError{Path: path, Err: errors.New("plugin has no Init function")}
+	}
+
+	s, err := info.Init(config)
+	if err != nil {
+		return nil, &LoadError{Path: path, Err: err}
+	}
+	return s, nil
+}
+
+// Plugins is an importer that loads Go plugins from a directory on
+// the host filesystem. Module names are slash-separated paths relative
+// to Dir, unless they are absolute, with ".so" appended. For example,
+// "lib/util" imports the plugin "lib/util.so" inside of Dir.
+//
+// Modules are cached, so each plugin is only initialized once per
+// Plugins. A plugin's Init function may import other plugins through
+// the importer in its config, including from the same Plugins. A
+// plugin that imports itself, directly or indirectly, results in a
+// *CycleError, provided that the import only passes through the
+// importers in this package on its way back to the Plugins. Otherwise,
+// the cycle can't be detected and the import never returns.
+type Plugins struct {
+	// Dir is the directory that plugins are loaded from. If it is
+	// empty, the current working directory is used.
+	Dir string
+
+	// Importer is passed to plugins in their config. It may be nil.
+	Importer wdte.Importer
+
+	// Options is passed to plugins in their config.
+	Options map[string]string
+
+	m     sync.Mutex
+	cache map[string]*pluginLoad
+}
+
+// pluginLoad is a plugin that has been loaded or is being loaded.
+// done is closed once the load has finished, after which scope and
+// err are set.
+type pluginLoad struct {
+	done  chan struct{}
+	scope *wdte.Scope
+	err   error
+}
+
+func (p *Plugins) Import(from string) (*wdte.Scope, error) {
+	return p.importStack(from, nil)
+}
+
+func (p *Plugins) importStack(from string, stack []loading) (*wdte.Scope, error) {
+	path := filepath.FromSlash(from)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.Dir, path)
+	}
+	path += ".so"
+
+	cur := loading{plugins: p, path: path}
+	if err := checkCycle(stack, cur); err != nil {
+		return nil, err
+	}
+
+	p.m.Lock()
+	l, ok := p.cache[path]
+	if !ok {
+		l = &pluginLoad{done: make(chan struct{})}
+		if p.cache == nil {
+			p.cache = make(map[string]*pluginLoad)
+		}
+		p.cache[path] = l
+	}
+	p.m.Unlock()
+
+	if ok {
+		<-l.done
+		return l.scope, l.err
+	}
+
+	// The lock isn't held while the plugin is loaded, as its Init
+	// function may import other plugins from p.
+	config := PluginConfig{Options: p.Options}
+	if p.Importer != nil {
+		config.Importer = boundImporter{im: p.Importer, stack: append(stack[:len(stack):len(stack)], cur)}
+	}
+	l.scope, l.err = LoadPlugin(path, config)
+	if l.err != nil {
+		p.m.Lock()
+		delete(p.cache, path)
+		p.m.Unlock()
+	}
+	close(l.done)
+
+	return l.scope, l.err
+}
@@ -0,0 +1,33 @@
+package importer
+
+import (
+	"errors"
+	"plugin"
+	"strings"
+)
+
+func openPlugin(path string) (*PluginInfo, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		if strings.Contains(err.Error(), "different version of package") {
+			return nil, &PluginVersionError{Path: path, Version: Version(), Err: err}
+		}
+		return nil, &LoadError{Path: path, Err: err}
+	}
+
+	sym, err := p.Lookup(PluginSymbol)
+	if err != nil {
+		return nil, &LoadError{Path: path, Err: err}
+	}
+
+	info, ok := sym.(*PluginInfo)
+	if !ok {
+		return nil, &PluginVersionError{
+			Path:    path,
+			Version: Version(),
+			Err:     errors.New(PluginSymbol + " symbol has wrong type"),
+		}
+	}
+
+	return info, nil
+}
@@ -0,0 +1,7 @@
+// +build !linux
+
+package importer
+
+func openPlugin(path string) (*PluginInfo, error) {
+	return nil, ErrPluginsUnsupported
+}
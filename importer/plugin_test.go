@@ -0,0 +1,135 @@
+// +build linux
+
+package importer_test
+
+import (
+	"errors"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DeedleFake/wdte"
+	"github.com/DeedleFake/wdte/importer"
+	"github.com/DeedleFake/wdte/std"
+	"github.com/DeedleFake/wdte/std/debug"
+)
+
+// buildPlugin builds the plugin in testdata/plugins/name into dir.
+func buildPlugin(t *testing.T, dir, name string) {
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	args := []string{"build", "-buildmode=plugin"}
+	if debug.RaceEnabled {
+		args = append(args, "-race")
+	}
+	args = append(args, "-o", filepath.Join(dir, name+".so"), "./testdata/plugins/"+name)
+
+	out, err := exec.Command(gobin, args...).CombinedOutput()
+	if err != nil {
+		t.Skipf("Failed to build plugin %q: %v\n%s", name, err, out)
+	}
+}
+
+func TestPlugins(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping plugin build in short mode")
+	}
+
+	dir := t.TempDir()
+	buildPlugin(t, dir, "good")
+	buildPlugin(t, dir, "badabi")
+	buildPlugin(t, dir, "nested")
+	buildPlugin(t, dir, "cycle")
+
+	host := importer.Modules{"host": wdte.S().Add("v", wdte.Number(3))}
+	im := &importer.Plugins{
+		Dir:      dir,
+		Importer: host,
+		Options:  map[string]string{"option": "set"},
+	}
+
+	t.Run("Load", func(t *testing.T) {
+		s, err := im.Import("good")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ret := s.Get("host").Call(std.F()); !wdte.Equal(ret, wdte.Number(3)) {
+			t.Errorf("Expected host value 3, but got %v", ret)
+		}
+		if ret := s.Get("option").Call(std.F()); !wdte.Equal(ret, wdte.String("set")) {
+			t.Errorf("Expected option set, but got %v", ret)
+		}
+
+		again, err := im.Import(filepath.ToSlash(filepath.Join(dir, "good")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again != s {
+			t.Errorf("Plugin was initialized twice")
+		}
+	})
+
+	t.Run("ABI", func(t *testing.T) {
+		_, err := im.Import("badabi")
+
+		var verr *importer.PluginVersionError
+		if !errors.As(err, &verr) {
+			t.Fatalf("Expected a version error, but got %v", err)
+		}
+		if verr.ABI != importer.PluginABI+1 {
+			t.Errorf("Expected ABI %v, but got %v", importer.PluginABI+1, verr.ABI)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := im.Import("missing")
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Expected a not found error, but got %v", err)
+		}
+	})
+	t.Run("Nested", func(t *testing.T) {
+		im := &importer.Plugins{Dir: dir}
+		im.Importer = importer.Chain{host, im}
+
+		var s *wdte.Scope
+		var err error
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			s, err = im.Import("nested")
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Minute):
+			t.Fatal("Import didn't return")
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ret := s.Get("host").Call(std.F()); !wdte.Equal(ret, wdte.Number(3)) {
+			t.Errorf("Expected host value 3, but got %v", ret)
+		}
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		im := &importer.Plugins{Dir: dir}
+		im.Importer = importer.Chain{host, im}
+
+		_, err := im.Import("cycle")
+
+		var cerr *importer.CycleError
+		if !errors.As(err, &cerr) {
+			t.Fatalf("Expected a cycle error, but got %v", err)
+		}
+		if len(cerr.Path) != 2 {
+			t.Errorf("Unexpected cycle: %v", cerr.Path)
+		}
+	})
+}
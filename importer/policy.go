@@ -0,0 +1,112 @@
+package importer
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/DeedleFake/wdte"
+	"github.com/DeedleFake/wdte/std"
+	_ "github.com/DeedleFake/wdte/std/arrays"
+	_ "github.com/DeedleFake/wdte/std/bytes"
+	wdteio "github.com/DeedleFake/wdte/std/io"
+	"github.com/DeedleFake/wdte/std/io/file"
+	_ "github.com/DeedleFake/wdte/std/math"
+	_ "github.com/DeedleFake/wdte/std/rand"
+	"github.com/DeedleFake/wdte/std/stream"
+	_ "github.com/DeedleFake/wdte/std/strings"
+)
+
+// A DeniedError is returned when a module is not allowed to be
+// imported by the importers returned by Allow and Deny.
+type DeniedError struct {
+	Module string
+}
+
+func (err *DeniedError) Error() string {
+	return fmt.Sprintf("import of %q is not allowed", err.Module)
+}
+
+// policy is an importer that only passes imports through to another
+// importer if they match, or don't match, a list of patterns.
+type policy struct {
+	im       wdte.Importer
+	patterns []string
+	allow    bool
+}
+
+// Allow returns an importer that only imports modules from im if
+// their names match at least one of the given patterns, returning a
+// DeniedError for all others. Patterns use the syntax of path.Match,
+// so, for example, "io/*" matches "io/file" but not "io".
+//
+// Note that Allow only restricts imports that go through the returned
+// importer. If scripts loaded by im can import modules using another
+// importer, such as an FS with a different Parent, those imports are
+// not restricted.
+func Allow(im wdte.Importer, patterns ...string) wdte.Importer {
+	return &policy{im: im, patterns: patterns, allow: true}
+}
+
+// Deny returns an importer that imports modules from im unless their
+// names match at least one of the given patterns, in which case a
+// DeniedError is returned. Patterns use the same syntax as Allow, and
+// the same caveats apply.
+func Deny(im wdte.Importer, patterns ...string) wdte.Importer {
+	return &policy{im: im, patterns: patterns}
+}
+
+func (p *policy) match(from string) bool {
+	for _, pattern := range p.patterns {
+		if ok, _ := path.Match(pattern, from); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *policy) Import(from string) (*wdte.Scope, error) {
+	return p.importStack(from, nil)
+}
+
+func (p *policy) importStack(from string, stack []loading) (*wdte.Scope, error) {
+	if p.match(from) != p.allow {
+		return nil, &DeniedError{Module: from}
+	}
+
+	return importStack(p.im, from, stack)
+}
+
+// sandboxModules are the modules from std that are made available by
+// Sandbox without modification.
+var sandboxModules = []string{
+	"arrays",
+	"bytes",
+	"math",
+	"rand",
+	"strings",
+}
+
+// Sandbox returns an importer intended for running untrusted scripts.
+// It provides the modules from std that don't give access to the host
+// system, as well as the io module without stdin and the stream module
+// without its parallel functions, such as pmap. If root is not empty,
+// it also provides a read-only io/file module restricted to files
+// inside of the directory root. All other imports are denied.
+//
+// The modules provided don't allow a script to start goroutines, but
+// Sandbox does nothing to limit the time or memory that a script can
+// use. To limit its running time, call it with wdte.Run.
+func Sandbox(root string) wdte.Importer {
+	modules := Modules{
+		"io":     wdteio.NoStdin,
+		"stream": stream.Sequential,
+	}
+	if root != "" {
+		modules["io/file"] = file.Restricted(root, true)
+	}
+
+	return Chain{
+		modules,
+		Allow(std.Import, sandboxModules...),
+	}
+}
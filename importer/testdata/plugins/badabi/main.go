@@ -0,0 +1,15 @@
+package main
+
+import (
+	"github.com/DeedleFake/wdte"
+	"github.com/DeedleFake/wdte/importer"
+)
+
+var WDTEPlugin = importer.PluginInfo{
+	ABI: importer.PluginABI + 1,
+	Init: func(config importer.PluginConfig) (*wdte.Scope, error) {
+		return wdte.S(), nil
+	},
+}
+
+func main() {}
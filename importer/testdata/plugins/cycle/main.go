@@ -0,0 +1,15 @@
+package main
+
+import (
+	"github.com/DeedleFake/wdte"
+	"github.com/DeedleFake/wdte/importer"
+)
+
+var WDTEPlugin = importer.PluginInfo{
+	ABI: importer.PluginABI,
+	Init: func(config importer.PluginConfig) (*wdte.Scope, error) {
+		return config.Importer.Import("cycle")
+	},
+}
+
+func main() {}
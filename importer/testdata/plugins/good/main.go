@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/DeedleFake/wdte"
+	"github.com/DeedleFake/wdte/importer"
+)
+
+var WDTEPlugin = importer.PluginInfo{
+	ABI: importer.PluginABI,
+	Init: func(config importer.PluginConfig) (*wdte.Scope, error) {
+		host, err := config.Importer.Import("host")
+		if err != nil {
+			return nil, err
+		}
+
+		return wdte.S().Map(map[wdte.ID]wdte.Func{
+			"host":   host.Get("v"),
+			"option": wdte.String(config.Options["option"]),
+		}), nil
+	},
+}
+
+func main() {}
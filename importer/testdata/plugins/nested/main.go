@@ -0,0 +1,20 @@
+package main
+
+import (
+	"github.com/DeedleFake/wdte"
+	"github.com/DeedleFake/wdte/importer"
+)
+
+var WDTEPlugin = importer.PluginInfo{
+	ABI: importer.PluginABI,
+	Init: func(config importer.PluginConfig) (*wdte.Scope, error) {
+		good, err := config.Importer.Import("good")
+		if err != nil {
+			return nil, err
+		}
+
+		return wdte.S().Add("host", good.Get("host")), nil
+	},
+}
+
+func main() {}
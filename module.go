@@ -0,0 +1,187 @@
+package wdte
+
+import "fmt"
+
+// ModuleID is the ID of the variable that a script can declare in
+// order to describe itself when it is imported as a module. Its value
+// must be a scope, such as one created with a collector, that may
+// contain any of the following variables:
+//
+//    name    # A string containing the name of the module.
+//    version # A string containing the version of the module.
+//    deps    # An array of strings naming the modules it depends on.
+//    exports # An array of strings naming the variables to export.
+//
+// For example,
+//
+//    let module => (|
+//      let name => 'greet';
+//      let version => '1.0.0';
+//      let exports => ['hello'];
+//    |);
+//
+//    let greeting => 'Hello, ';
+//    let hello name => + greeting name;
+//
+// See Export for more information.
+const ModuleID ID = "module"
+
+// ModuleInfo is the metadata declared by a module.
+type ModuleInfo struct {
+	// Name is the name that the module declared for itself. It need not
+	// match the name it was imported by.
+	Name string
+
+	// Version is the version of the module. It is not interpreted.
+	Version string
+
+	// Deps is a list of the names of the modules that the module
+	// depends on. It is purely informational, and is not checked
+	// against the module's actual imports.
+	Deps []string
+
+	// Exports is the list of variables that the module exports. If it
+	// is nil, every variable is exported.
+	Exports []ID
+}
+
+// Module returns the metadata of the module that s belongs to, or nil
+// if s doesn't belong to a module with metadata. Metadata is attached
+// to a scope by Export or WithModule, and is inherited by subscopes.
+func (s *Scope) Module() *ModuleInfo {
+	for ; s != nil; s = s.p {
+		if s.mod != nil {
+			return s.mod
+		}
+	}
+
+	return nil
+}
+
+// WithModule returns a subscope of s with the given metadata attached
+// to it.
+func (s *Scope) WithModule(info *ModuleInfo) *Scope {
+	return &Scope{
+		p:   s,
+		mod: info,
+	}
+}
+
+// Export converts s, the scope collected from a script's top-level
+// let expressions, into the scope that should be given to importers
+// of that script. If the script declares a variable with the ID
+// ModuleID, its value is evaluated and used as the module's metadata,
+// which is attached to the returned scope and can be retrieved with
+// its Module method.
+//
+// If the metadata contains a list of exports, only those variables
+// are present in the returned scope, and it is an error for any of
+// them to not exist. Otherwise, every variable in s is present. In
+// either case, the variable named by ModuleID itself is not.
+func Export(frame Frame, s *Scope) (*Scope, error) {
+	frame = frame.Sub("export")
+
+	info, err := moduleInfo(frame, s.Get(ModuleID))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := s.Known()
+	if (info != nil) && (info.Exports != nil) {
+		ids = info.Exports
+	}
+
+	vars := make(map[ID]Func, len(ids))
+	for _, id := range ids {
+		if id == ModuleID {
+			continue
+		}
+
+		v := s.Get(id)
+		if v == nil {
+			return nil, fmt.Errorf("exported variable %q does not exist", id)
+		}
+		vars[id] = v
+	}
+
+	exported := S().Map(vars)
+	if info != nil {
+		exported = exported.WithModule(info)
+	}
+	return exported, nil
+}
+
+func moduleInfo(frame Frame, m Func) (*ModuleInfo, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	ms, ok := m.Call(frame).(*Scope)
+	if !ok {
+		return nil, fmt.Errorf("%v must be a scope", ModuleID)
+	}
+
+	var info ModuleInfo
+	if err := moduleString(frame, ms, "name", &info.Name); err != nil {
+		return nil, err
+	}
+	if err := moduleString(frame, ms, "version", &info.Version); err != nil {
+		return nil, err
+	}
+
+	deps, err := moduleStrings(frame, ms, "deps")
+	if err != nil {
+		return nil, err
+	}
+	info.Deps = deps
+
+	exports, err := moduleStrings(frame, ms, "exports")
+	if err != nil {
+		return nil, err
+	}
+	if exports != nil {
+		info.Exports = make([]ID, 0, len(exports))
+		for _, e := range exports {
+			info.Exports = append(info.Exports, ID(e))
+		}
+	}
+
+	return &info, nil
+}
+
+func moduleString(frame Frame, ms *Scope, id ID, out *string) error {
+	v := ms.Get(id)
+	if v == nil {
+		return nil
+	}
+
+	str, ok := v.Call(frame).(String)
+	if !ok {
+		return fmt.Errorf("%v.%v must be a string", ModuleID, id)
+	}
+
+	*out = string(str)
+	return nil
+}
+
+func moduleStrings(frame Frame, ms *Scope, id ID) ([]string, error) {
+	v := ms.Get(id)
+	if v == nil {
+		return nil, nil
+	}
+
+	a, ok := v.Call(frame).(Array)
+	if !ok {
+		return nil, fmt.Errorf("%v.%v must be an array of strings", ModuleID, id)
+	}
+
+	strs := make([]string, 0, len(a))
+	for _, e := range a {
+		str, ok := e.Call(frame).(String)
+		if !ok {
+			return nil, fmt.Errorf("%v.%v must be an array of strings", ModuleID, id)
+		}
+		strs = append(strs, string(str))
+	}
+	return strs, nil
+}
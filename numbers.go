@@ -0,0 +1,309 @@
+package wdte
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// An Int is a 64-bit signed integer, as parsed from a number literal
+// with an i suffix, such as 3i. Unlike Number, it is exact, making it
+// suitable for things like IDs and bit manipulation.
+type Int int64
+
+func (i Int) Call(frame Frame, args ...Func) Func {
+	return i
+}
+
+func (i Int) Compare(other Func) (int, bool) {
+	return compareNumeric(i, other)
+}
+
+func (i Int) Hash() uint64 {
+	return hashUint(uint64(i))
+}
+
+func (i Int) String() string {
+	return strconv.FormatInt(int64(i), 10)
+}
+
+func (i Int) Reflect(name string) bool {
+	return name == "Int"
+}
+
+// A BigInt is an arbitrary-precision integer, as parsed from a number
+// literal with an n suffix, such as 3n. A BigInt should be treated as
+// immutable once it has been created.
+type BigInt struct {
+	*big.Int
+}
+
+func (i BigInt) Call(frame Frame, args ...Func) Func {
+	return i
+}
+
+func (i BigInt) Compare(other Func) (int, bool) {
+	return compareNumeric(i, other)
+}
+
+func (i BigInt) Hash() uint64 {
+	return hashBigInt(i.Int)
+}
+
+func (i BigInt) Reflect(name string) bool {
+	return name == "BigInt"
+}
+
+// A BigRat is an arbitrary-precision rational number, as parsed from
+// a number literal with an r suffix, such as 1.25r. It is also the
+// result of dividing integers that don't divide evenly. A BigRat
+// should be treated as immutable once it has been created.
+type BigRat struct {
+	*big.Rat
+}
+
+func (r BigRat) Call(frame Frame, args ...Func) Func {
+	return r
+}
+
+func (r BigRat) Compare(other Func) (int, bool) {
+	return compareNumeric(r, other)
+}
+
+func (r BigRat) Hash() uint64 {
+	if r.IsInt() {
+		return hashBigInt(r.Num())
+	}
+
+	f, exact := r.Float64()
+	if exact {
+		return Number(f).Hash()
+	}
+
+	return hashString(r.Rat.String())
+}
+
+func (r BigRat) String() string {
+	return r.RatString()
+}
+
+func (r BigRat) Reflect(name string) bool {
+	return name == "BigRat"
+}
+
+func hashBigInt(i *big.Int) uint64 {
+	if i.IsInt64() {
+		return hashUint(uint64(i.Int64()))
+	}
+
+	f, acc := new(big.Float).SetInt(i).Float64()
+	if acc == big.Exact {
+		return Number(f).Hash()
+	}
+
+	return hashString(i.String())
+}
+
+// numericRank returns the position of f in the numeric promotion
+// tower, or -1 if f isn't a numeric type.
+func numericRank(f Func) int {
+	switch f.(type) {
+	case Int:
+		return 0
+	case BigInt:
+		return 1
+	case BigRat:
+		return 2
+	case Number:
+		return 3
+	default:
+		return -1
+	}
+}
+
+func promoteTo(f Func, rank int) Func {
+	switch rank {
+	case 0:
+		return f
+
+	case 1:
+		switch f := f.(type) {
+		case Int:
+			return BigInt{big.NewInt(int64(f))}
+		}
+
+	case 2:
+		switch f := f.(type) {
+		case Int:
+			return BigRat{new(big.Rat).SetInt64(int64(f))}
+		case BigInt:
+			return BigRat{new(big.Rat).SetInt(f.Int)}
+		}
+
+	case 3:
+		switch f := f.(type) {
+		case Int:
+			return Number(f)
+		case BigInt:
+			v, _ := new(big.Float).SetInt(f.Int).Float64()
+			return Number(v)
+		case BigRat:
+			v, _ := f.Float64()
+			return Number(v)
+		}
+	}
+
+	return f
+}
+
+// Promote converts the numeric values a and b to a common type using
+// the numeric promotion tower, which, from lowest to highest, is Int,
+// BigInt, BigRat, and Number. The value with the lower type is
+// converted to the type of the higher one. For example, promoting an
+// Int and a BigRat results in two BigRats. Note that promoting to a
+// Number may result in a loss of precision.
+//
+// If either a or b is not one of the numeric types, ok is false.
+func Promote(a, b Func) (pa, pb Func, ok bool) {
+	ra, rb := numericRank(a), numericRank(b)
+	if (ra < 0) || (rb < 0) {
+		return a, b, false
+	}
+
+	rank := ra
+	if rb > rank {
+		rank = rb
+	}
+
+	return promoteTo(a, rank), promoteTo(b, rank), true
+}
+
+func compareNumeric(a, b Func) (int, bool) {
+	a, b, ok := exactNumbers(a, b)
+	if !ok {
+		a, b, ok = Promote(a, b)
+		if !ok {
+			return -1, false
+		}
+	}
+
+	switch a := a.(type) {
+	case Int:
+		b := b.(Int)
+		switch {
+		case a < b:
+			return -1, true
+		case a > b:
+			return 1, true
+		}
+		return 0, true
+
+	case BigInt:
+		return a.Cmp(b.(BigInt).Int), true
+
+	case BigRat:
+		return a.Cmp(b.(BigRat).Rat), true
+
+	case Number:
+		b := b.(Number)
+		switch {
+		case a < b:
+			return -1, true
+		case a > b:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	panic(fmt.Errorf("unexpected numeric type: %T", a))
+}
+
+// exactNumbers converts a and b to BigRats if one of them is a finite
+// Number and the other is one of the exact numeric types. Promoting
+// the exact value to a Number instead could lose precision, causing
+// values that hash differently to compare as equal.
+func exactNumbers(a, b Func) (Func, Func, bool) {
+	ra, rb := numericRank(a), numericRank(b)
+	if (ra < 0) || (rb < 0) || ((ra == 3) == (rb == 3)) {
+		return a, b, false
+	}
+
+	for _, n := range []Func{a, b} {
+		if n, ok := n.(Number); ok && (math.IsNaN(float64(n)) || math.IsInf(float64(n), 0)) {
+			return a, b, false
+		}
+	}
+
+	return toRat(a), toRat(b), true
+}
+
+// toRat converts the numeric value f to a BigRat exactly.
+func toRat(f Func) Func {
+	if n, ok := f.(Number); ok {
+		return BigRat{new(big.Rat).SetFloat64(float64(n))}
+	}
+	return promoteTo(f, 2)
+}
+
+// ToInt converts the numeric value f to an int. It returns an error
+// if f isn't a number or if its value isn't an integer that fits in
+// an int.
+func ToInt(f Func) (int, error) {
+	var v int64
+	switch f := f.(type) {
+	case Int:
+		v = int64(f)
+
+	case Number:
+		x := float64(f)
+		if x != math.Trunc(x) {
+			return 0, fmt.Errorf("%v is not an integer", f)
+		}
+		if (x < -(1 << 63)) || (x >= 1<<63) {
+			return 0, fmt.Errorf("%v is out of range", f)
+		}
+		v = int64(x)
+
+	case BigInt:
+		if !f.IsInt64() {
+			return 0, fmt.Errorf("%v is out of range", f)
+		}
+		v = f.Int64()
+
+	case BigRat:
+		if !f.IsInt() {
+			return 0, fmt.Errorf("%v is not an integer", f)
+		}
+		if !f.Num().IsInt64() {
+			return 0, fmt.Errorf("%v is out of range", f)
+		}
+		v = f.Num().Int64()
+
+	default:
+		return 0, fmt.Errorf("%v is not a number", f)
+	}
+
+	if int64(int(v)) != v {
+		return 0, fmt.Errorf("%v is out of range", f)
+	}
+	return int(v), nil
+}
+
+// ToFloat converts the numeric value f to a float64, which may lose
+// precision. It returns an error if f isn't a number.
+func ToFloat(f Func) (float64, error) {
+	if numericRank(f) < 0 {
+		return 0, fmt.Errorf("%v is not a number", f)
+	}
+	return float64(promoteTo(f, 3).(Number)), nil
+}
+
+// toIndex converts a numeric Func into an int for use as an index.
+func toIndex(f Func) (int, error) {
+	i, err := ToInt(f)
+	if err != nil {
+		return 0, fmt.Errorf("invalid index: %w", err)
+	}
+	return i, nil
+}
@@ -0,0 +1,223 @@
+// Package profile provides a profiler for WDTE scripts that produces
+// profiles in the format used by pprof, allowing them to be examined
+// with tools such as go tool pprof.
+//
+// For example, to profile a script:
+//
+//    var p profile.Profiler
+//    m.Call(std.F().WithTracer(&p))
+//
+//    f, err := os.Create("cpu.pprof")
+//    if err != nil {
+//      return err
+//    }
+//    defer f.Close()
+//
+//    return p.WriteProfile(f)
+//
+// The resulting file can then be viewed with, for example,
+//
+//    go tool pprof -http :8080 cpu.pprof
+package profile
+
+import (
+	"fmt"
+	"io"
+	"runtime/metrics"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DeedleFake/wdte"
+)
+
+// allocMetric is the runtime metric used to measure allocations.
+const allocMetric = "/gc/heap/allocs:bytes"
+
+// A Profiler is a wdte.Tracer that measures the time spent in and the
+// memory allocated by each function called while evaluating WDTE
+// code. Each call is attributed to the stack of WDTE functions that
+// were active when it was made, as given by wdte.Frame.TracedCall,
+// and functions are named as described by wdte.TracedCall.Name.
+// Lambdas, including functions declared with let, are recorded as
+// starting on the line that they were declared on, and the line that
+// each call in a lambda was made from is recorded as the lambda's
+// location in the stacks of the calls made by it.
+//
+// Time and allocations are measured between function calls and
+// returns, rather than by sampling, so every call is recorded, but
+// the overhead of measuring them is included. Allocations are
+// measured for the whole process, so they include memory allocated
+// by other goroutines.
+//
+// The zero value of a Profiler is ready to use. Profiling starts when
+// the first call is made. A Profiler is safe for concurrent use,
+// including by code that evaluates functions on several goroutines at
+// once, such as with the sync module. In that case, the time spent in
+// a function's calls may add up to more than the time spent in the
+// function itself, so the time attributed to the function alone is
+// only what is left over, if anything.
+type Profiler struct {
+	// Filename is recorded in the profile as the source file of every
+	// function. It is typically the path of the script being profiled.
+	Filename string
+
+	m       sync.Mutex
+	start   time.Time
+	calls   map[*wdte.TracedCall]*call
+	samples map[string]*sample
+	metric  []metrics.Sample
+}
+
+// call is a function call that hasn't returned yet.
+type call struct {
+	start time.Time
+	alloc uint64
+
+	// line is the line that the function was declared on, or 0 if it
+	// isn't known.
+	line int
+
+	// childTime and childAlloc are the time spent in and the memory
+	// allocated by calls made by this one.
+	childTime  time.Duration
+	childAlloc uint64
+}
+
+// location is an entry in the stack of a sample.
+type location struct {
+	name string
+	line int
+
+	// start is the line that the function was declared on.
+	start int
+}
+
+// sample is the data recorded for a single stack.
+type sample struct {
+	stack []location // Leaf first.
+	calls int64
+	time  time.Duration
+	alloc uint64
+}
+
+func (p *Profiler) init() {
+	if p.samples != nil {
+		return
+	}
+
+	p.start = time.Now()
+	p.calls = make(map[*wdte.TracedCall]*call)
+	p.samples = make(map[string]*sample)
+	p.metric = []metrics.Sample{{Name: allocMetric}}
+}
+
+// allocs returns the total number of bytes allocated by the process.
+func (p *Profiler) allocs() uint64 {
+	metrics.Read(p.metric)
+	if p.metric[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return p.metric[0].Value.Uint64()
+}
+
+// stack returns the stack of tc, leaf first, and the key that its
+// sample is stored under.
+func (p *Profiler) stack(tc *wdte.TracedCall) ([]location, string) {
+	stack := make([]location, 0, tc.Depth+1)
+	var key strings.Builder
+
+	var child *wdte.TracedCall
+	for cur := tc; cur != nil; child, cur = cur, cur.Parent {
+		var start int
+		if c := p.calls[cur]; c != nil {
+			start = c.line
+		}
+
+		// The position of a call is only in the source of the function
+		// that made it if that function is a lambda.
+		line := start
+		if child != nil {
+			line = 0
+			if start != 0 {
+				line = child.Pos.Line
+			}
+		}
+
+		stack = append(stack, location{name: string(cur.Name), line: line, start: start})
+		fmt.Fprintf(&key, "%v\x00%v\x00%v\x00", cur.Name, line, start)
+	}
+
+	return stack, key.String()
+}
+
+func (p *Profiler) Enter(frame wdte.Frame, f wdte.Func, args []wdte.Func) {
+	tc := frame.TracedCall()
+	if tc == nil {
+		return
+	}
+
+	c := call{start: time.Now()}
+	if lambda, ok := f.(*wdte.Lambda); ok {
+		c.line = lambda.Pos.Line
+	}
+
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	p.init()
+	c.alloc = p.allocs()
+	p.calls[tc] = &c
+}
+
+func (p *Profiler) Exit(frame wdte.Frame, f wdte.Func, args []wdte.Func, r wdte.Func) {
+	tc := frame.TracedCall()
+
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	c := p.calls[tc]
+	if c == nil {
+		return
+	}
+
+	elapsed := time.Since(c.start)
+	alloc := p.allocs() - c.alloc
+
+	stack, key := p.stack(tc)
+	s := p.samples[key]
+	if s == nil {
+		s = &sample{stack: stack}
+		p.samples[key] = s
+	}
+	s.calls++
+	if elapsed > c.childTime {
+		s.time += elapsed - c.childTime
+	}
+	if alloc > c.childAlloc {
+		s.alloc += alloc - c.childAlloc
+	}
+
+	delete(p.calls, tc)
+	if parent := p.calls[tc.Parent]; parent != nil {
+		parent.childTime += elapsed
+		parent.childAlloc += alloc
+	}
+}
+
+func (p *Profiler) Lookup(frame wdte.Frame, id wdte.ID, f wdte.Func) {}
+
+func (p *Profiler) Error(frame wdte.Frame, err error) {}
+
+// WriteProfile writes the data collected so far to w as a
+// gzip-compressed pprof profile. The profile contains three sample
+// types: the number of calls, the time spent in them, which is the
+// default, and the number of bytes allocated by them. Calls that are
+// still in progress are not included.
+func (p *Profiler) WriteProfile(w io.Writer) error {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	p.init()
+	return writeProfile(w, p.Filename, p.start, time.Since(p.start), p.samples)
+}
@@ -0,0 +1,167 @@
+package profile_test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/DeedleFake/wdte"
+	"github.com/DeedleFake/wdte/profile"
+	"github.com/DeedleFake/wdte/std"
+	_ "github.com/DeedleFake/wdte/std/stream"
+)
+
+// pprof profiles script and returns the output of go tool pprof run
+// on the result with the given arguments.
+func pprof(t *testing.T, script string, expected wdte.Func, args ...string) string {
+	if testing.Short() {
+		t.Skip("Skipping pprof test in short mode.")
+	}
+
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not available")
+	}
+
+	m, err := wdte.Parse(strings.NewReader(script), std.Import, nil)
+	if err != nil {
+		t.Fatalf("Failed to parse script: %v", err)
+	}
+
+	p := profile.Profiler{Filename: "test.wdte"}
+	r := m.Call(std.F().WithTracer(&p))
+	if !wdte.Equal(r, expected) {
+		t.Fatalf("Expected %v, but got %v", expected, r)
+	}
+
+	path := filepath.Join(t.TempDir(), "cpu.pprof")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.WriteProfile(file)
+	file.Close()
+	if err != nil {
+		t.Fatalf("Failed to write profile: %v", err)
+	}
+
+	var out bytes.Buffer
+	cmd := exec.Command(gobin, append(append([]string{"tool", "pprof", "-symbolize=none", "-sample_index=calls"}, args...), path)...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("pprof failed: %v\n%s", err, &out)
+	}
+	return out.String()
+}
+
+// top parses the output of pprof -top, returning the flat values of
+// each entry by name.
+func top(out string) map[string]string {
+	flat := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if (len(fields) >= 6) && strings.HasSuffix(fields[1], "%") {
+			flat[strings.Join(fields[5:], " ")] = fields[0]
+		}
+	}
+	return flat
+}
+
+func TestProfiler(t *testing.T) {
+	const script = `
+let fib n => n {
+	<= 1 => n;
+	true => + (fib (- n 1)) (fib (- n 2));
+};
+fib 10;
+`
+
+	t.Run("Calls", func(t *testing.T) {
+		out := pprof(t, script, wdte.Number(55), "-top")
+		calls := top(out)
+
+		// fib 10 makes 177 calls to fib, 88 of which recurse, each of
+		// which makes one call to + and two to -.
+		expected := map[string]string{
+			"fib": "177",
+			"+":   "88",
+			"-":   "176",
+		}
+		for name, n := range expected {
+			if calls[name] != n {
+				t.Errorf("Expected %v calls to %v, but got %q\n%s", n, name, calls[name], out)
+			}
+		}
+	})
+
+	t.Run("Lines", func(t *testing.T) {
+		out := pprof(t, script, wdte.Number(55), "-top", "-lines")
+		calls := top(out)
+
+		// Calls to fib are recorded at the line that it's declared on,
+		// while the calls that it makes are recorded at the lines that
+		// it makes them from.
+		expected := map[string]string{
+			"fib test.wdte:2": "177",
+			"fib test.wdte:3": "0",
+			"fib test.wdte:4": "0",
+		}
+		for name, n := range expected {
+			if calls[name] != n {
+				t.Errorf("Expected %v calls at %v, but got %q\n%s", n, name, calls[name], out)
+			}
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		const script = `
+let s => import 'stream';
+let double n => * n 2;
+s.range 100
+-> s.pmap 4 double
+-> s.reduce 0 +;
+`
+
+		out := pprof(t, script, wdte.Number(9900), "-traces")
+
+		// Each trace is a count and a leaf followed by the rest of the
+		// stack, one per line. Every call to * has to be attributed to
+		// double, no matter which goroutine it was made on.
+		var calls int
+		for _, trace := range strings.Split(out, "-----------+")[1:] {
+			var count string
+			var stack []string
+			for _, line := range strings.Split(trace, "\n")[1:] {
+				fields := strings.Fields(line)
+				switch len(fields) {
+				case 1:
+					stack = append(stack, fields[0])
+				case 2:
+					count = fields[0]
+					stack = append(stack, fields[1])
+				}
+			}
+			if (len(stack) == 0) || (stack[0] != "*") {
+				continue
+			}
+
+			if (len(stack) < 2) || (stack[1] != "double") {
+				t.Errorf("Calls to * attributed to %q\n%s", stack, out)
+				continue
+			}
+			n, err := strconv.Atoi(count)
+			if err != nil {
+				t.Fatalf("Failed to parse count %q: %v", count, err)
+			}
+			calls += n
+		}
+		if calls != 100 {
+			t.Errorf("Expected 100 calls to * from double, but got %v\n%s", calls, out)
+		}
+	})
+}
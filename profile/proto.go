@@ -0,0 +1,220 @@
+package profile
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"sort"
+	"time"
+)
+
+// Field numbers from pprof's profile.proto.
+const (
+	profileSampleType        = 1
+	profileSample            = 2
+	profileLocation          = 4
+	profileFunction          = 5
+	profileStringTable       = 6
+	profileTimeNanos         = 9
+	profileDurationNanos     = 10
+	profilePeriodType        = 11
+	profilePeriod            = 12
+	profileDefaultSampleType = 14
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	locationID   = 1
+	locationLine = 4
+
+	lineFunctionID = 1
+	lineLine       = 2
+
+	functionID         = 1
+	functionName       = 2
+	functionSystemName = 3
+	functionFilename   = 4
+	functionStartLine  = 5
+)
+
+// protobuf is a minimal protocol buffer encoder.
+type protobuf struct {
+	data []byte
+}
+
+func (b *protobuf) varint(v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	b.data = append(b.data, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+func (b *protobuf) key(field, wire int) {
+	b.varint(uint64(field<<3 | wire))
+}
+
+func (b *protobuf) uint64(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	b.key(field, 0)
+	b.varint(v)
+}
+
+func (b *protobuf) int64(field int, v int64) {
+	b.uint64(field, uint64(v))
+}
+
+func (b *protobuf) bytes(field int, data []byte) {
+	b.key(field, 2)
+	b.varint(uint64(len(data)))
+	b.data = append(b.data, data...)
+}
+
+func (b *protobuf) string(field int, s string) {
+	b.bytes(field, []byte(s))
+}
+
+func (b *protobuf) packed(field int, vals []uint64) {
+	var p protobuf
+	for _, v := range vals {
+		p.varint(v)
+	}
+	b.bytes(field, p.data)
+}
+
+func (b *protobuf) message(field int, encode func(*protobuf)) {
+	var m protobuf
+	encode(&m)
+	b.bytes(field, m.data)
+}
+
+// stringTable assigns indices to the strings in a profile.
+type stringTable struct {
+	strs  []string
+	index map[string]int64
+}
+
+func (t *stringTable) get(s string) int64 {
+	if t.index == nil {
+		t.index = make(map[string]int64)
+	}
+
+	if i, ok := t.index[s]; ok {
+		return i
+	}
+
+	i := int64(len(t.strs))
+	t.strs = append(t.strs, s)
+	t.index[s] = i
+	return i
+}
+
+func writeProfile(w io.Writer, filename string, start time.Time, dur time.Duration, samples map[string]*sample) error {
+	var strs stringTable
+	strs.get("")
+
+	var b protobuf
+	valueType := func(field int, typ, unit string) {
+		b.message(field, func(m *protobuf) {
+			m.int64(valueTypeType, strs.get(typ))
+			m.int64(valueTypeUnit, strs.get(unit))
+		})
+	}
+	valueType(profileSampleType, "calls", "count")
+	valueType(profileSampleType, "time", "nanoseconds")
+	valueType(profileSampleType, "alloc_space", "bytes")
+
+	keys := make([]string, 0, len(samples))
+	for key := range samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Functions are identified by their names and the lines that they
+	// start on, and locations by their functions and lines.
+	type function struct {
+		name  string
+		start int
+	}
+	type loc struct {
+		fn   uint64
+		line int
+	}
+	funcIDs := make(map[function]uint64)
+	locIDs := make(map[loc]uint64)
+	var funcs []function
+	var locations []loc
+	for _, key := range keys {
+		s := samples[key]
+
+		ids := make([]uint64, 0, len(s.stack))
+		for _, l := range s.stack {
+			f := function{name: l.name, start: l.start}
+			fid, ok := funcIDs[f]
+			if !ok {
+				funcs = append(funcs, f)
+				fid = uint64(len(funcs))
+				funcIDs[f] = fid
+			}
+
+			lc := loc{fn: fid, line: l.line}
+			id, ok := locIDs[lc]
+			if !ok {
+				locations = append(locations, lc)
+				id = uint64(len(locations))
+				locIDs[lc] = id
+			}
+			ids = append(ids, id)
+		}
+
+		b.message(profileSample, func(m *protobuf) {
+			m.packed(sampleLocationID, ids)
+			m.packed(sampleValue, []uint64{
+				uint64(s.calls),
+				uint64(s.time),
+				s.alloc,
+			})
+		})
+	}
+
+	for i, l := range locations {
+		id := uint64(i + 1)
+		b.message(profileLocation, func(m *protobuf) {
+			m.uint64(locationID, id)
+			m.message(locationLine, func(m *protobuf) {
+				m.uint64(lineFunctionID, l.fn)
+				m.int64(lineLine, int64(l.line))
+			})
+		})
+	}
+	for i, f := range funcs {
+		id := uint64(i + 1)
+		b.message(profileFunction, func(m *protobuf) {
+			m.uint64(functionID, id)
+			m.int64(functionName, strs.get(f.name))
+			m.int64(functionSystemName, strs.get(f.name))
+			m.int64(functionFilename, strs.get(filename))
+			m.int64(functionStartLine, int64(f.start))
+		})
+	}
+
+	b.int64(profileTimeNanos, start.UnixNano())
+	b.int64(profileDurationNanos, int64(dur))
+	valueType(profilePeriodType, "time", "nanoseconds")
+	b.int64(profilePeriod, 1)
+	b.int64(profileDefaultSampleType, strs.get("time"))
+
+	// The string table has to be written last, as the other fields add
+	// to it.
+	for _, s := range strs.strs {
+		b.string(profileStringTable, s)
+	}
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(b.data); err != nil {
+		return err
+	}
+	return gz.Close()
+}
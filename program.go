@@ -0,0 +1,143 @@
+package wdte
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/DeedleFake/wdte/ast"
+	"github.com/DeedleFake/wdte/scanner"
+)
+
+// A Program is a script that has been parsed and translated once so
+// that it can be run any number of times, possibly concurrently, with
+// different scopes, contexts, and importers. Unlike with Parse, all of
+// a Program's imports, including those of string literals, are done
+// at runtime using the importer of the frame that it is instantiated
+// with. See Frame.WithImporter.
+//
+// For example, to handle requests with a script that defines a handle
+// function:
+//
+//    p, err := wdte.Compile(r, nil)
+//    if err != nil {
+//      return err
+//    }
+//
+//    base := std.F().WithImporter(std.Import)
+//    for req := range requests {
+//      inst, err := p.Instantiate(base.WithScope(requestScope(req)))
+//      if err != nil {
+//        return err
+//      }
+//
+//      resp := inst.CallContext(req.Context(), "handle", wdte.String(req.Path))
+//      ...
+//    }
+//
+// Because the frame importer caches modules, instantiating a Program
+// multiple times with frames derived from the same call to
+// WithImporter only imports each module once.
+type Program struct {
+	c Compound
+}
+
+// Compile parses and translates a script from r into a Program.
+func Compile(r io.Reader, macros scanner.MacroMap) (*Program, error) {
+	root, err := ast.Parse(r, macros)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := (&translator{
+		runtimeImports: true,
+	}).fromScript(root.(*ast.NTerm))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Program{c: c}, nil
+}
+
+// Compound returns the top-level compound of the program.
+func (p *Program) Compound() Compound {
+	return p.c
+}
+
+// Instantiate evaluates the top-level expressions of the program
+// using frame, returning an Instance that can be used to access the
+// variables that it declares. If the evaluation results in an error,
+// that error is returned.
+func (p *Program) Instantiate(frame Frame) (*Instance, error) {
+	s, last := p.c.Collect(frame)
+	if err, ok := last.(error); ok {
+		return nil, err
+	}
+
+	return &Instance{
+		frame:  frame.WithScope(frame.Scope().Sub(s)),
+		scope:  s,
+		result: last,
+	}, nil
+}
+
+// An Instance is the result of evaluating a Program. It is safe for
+// concurrent use, though the values that it contains might not be.
+type Instance struct {
+	frame  Frame
+	scope  *Scope
+	result Func
+}
+
+// Scope returns the scope collected from the top-level let
+// expressions of the program.
+func (i *Instance) Scope() *Scope {
+	return i.scope
+}
+
+// Frame returns the frame that the instance's variables are called
+// with, which contains both the scope that the instance was created
+// with and the instance's own variables.
+func (i *Instance) Frame() Frame {
+	return i.frame
+}
+
+// Result returns the value of the last top-level expression of the
+// program.
+func (i *Instance) Result() Func {
+	return i.result
+}
+
+// Lookup returns the value of the top-level variable with the given
+// ID, or nil if the program didn't declare it.
+func (i *Instance) Lookup(id ID) Func {
+	return i.scope.Get(id)
+}
+
+// Call calls the top-level variable with the given ID with args. If
+// there is no such variable, an error is returned.
+func (i *Instance) Call(id ID, args ...Func) Func {
+	frame := i.frame.Sub(id)
+
+	f := i.Lookup(id)
+	if f == nil {
+		return &Error{
+			Err:   fmt.Errorf("%q is not defined", id),
+			Frame: frame,
+		}
+	}
+
+	return f.Call(frame, args...)
+}
+
+// CallContext is like Call, but uses ctx as described by Run.
+func (i *Instance) CallContext(ctx context.Context, id ID, args ...Func) Func {
+	inst := *i
+	inst.frame = i.frame.WithContext(ctx)
+
+	r := inst.Call(id, args...)
+	if err, ok := inst.frame.Cancelled(); ok {
+		return err
+	}
+	return r
+}
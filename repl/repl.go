@@ -52,6 +52,7 @@ type REPL struct {
 	next   NextFunc
 	im     wdte.Importer
 	macros scanner.MacroMap
+	frame  wdte.Frame
 
 	stack []string
 	buf   []byte
@@ -64,6 +65,7 @@ func New(next NextFunc, im wdte.Importer, macros scanner.MacroMap, start *wdte.S
 		next:   next,
 		im:     im,
 		macros: macros,
+		frame:  wdte.F().WithImporter(im),
 		Scope:  start,
 	}
 }
@@ -110,7 +112,7 @@ func (r *REPL) Next() (ret wdte.Func, err error) {
 		return nil, err
 	}
 
-	frame := wdte.F().WithScope(r.Scope)
+	frame := r.frame.WithScope(r.Scope)
 	next, ret := m.Collect(frame)
 	if err, ok := ret.(error); ok {
 		return nil, err
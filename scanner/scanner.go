@@ -99,7 +99,7 @@ func (s *Scanner) Scan() bool {
 		state = state(r)
 	}
 
-	return true
+	return s.err == nil
 }
 
 // Tok returns the latest token scanned. If there was an error or a
@@ -271,6 +271,17 @@ func (s *Scanner) number(r rune) stateFunc {
 		return s.number
 	}
 
+	switch r {
+	case 'i', 'n', 'r':
+		val, err := parseNumber(s.tbuf.String(), r)
+		if err != nil {
+			s.err = err
+			return nil
+		}
+		s.setTok(Number, val)
+		return nil
+	}
+
 	val, _ := strconv.ParseFloat(s.tbuf.String(), 64)
 	s.setTok(Number, val)
 
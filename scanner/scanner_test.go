@@ -131,6 +131,16 @@ o => print "double\n" 'single\\';`,
 				{Type: scanner.EOF, Val: nil},
 			},
 		},
+		{
+			name: "NumberSuffix",
+			in:   `3i -9007199254740993i;`,
+			out: []scanner.Token{
+				{Type: scanner.Number, Val: int64(3)},
+				{Type: scanner.Number, Val: int64(-9007199254740993)},
+				{Type: scanner.Keyword, Val: ";"},
+				{Type: scanner.EOF},
+			},
+		},
 		{
 			name: "LongSymbol",
 			in:   `(@`,
@@ -1,7 +1,10 @@
 package scanner
 
 import (
+	"fmt"
+	"math/big"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -90,3 +93,32 @@ func endQuote(r rune) rune {
 		return r
 	}
 }
+
+// parseNumber parses a number literal with a type suffix. The suffix
+// i results in an int64, n in a *big.Int, and r in a *big.Rat.
+func parseNumber(str string, suffix rune) (interface{}, error) {
+	switch suffix {
+	case 'i':
+		v, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer literal %q: %v", str+"i", err)
+		}
+		return v, nil
+
+	case 'n':
+		v, ok := new(big.Int).SetString(str, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid big integer literal %q", str+"n")
+		}
+		return v, nil
+
+	case 'r':
+		v, ok := new(big.Rat).SetString(str)
+		if !ok {
+			return nil, fmt.Errorf("invalid rational literal %q", str+"r")
+		}
+		return v, nil
+	}
+
+	panic(fmt.Errorf("invalid number suffix: %q", suffix))
+}
@@ -4,6 +4,7 @@ package all
 
 import (
 	_ "github.com/DeedleFake/wdte/std/arrays"
+	_ "github.com/DeedleFake/wdte/std/bytes"
 	_ "github.com/DeedleFake/wdte/std/debug"
 	_ "github.com/DeedleFake/wdte/std/io"
 	_ "github.com/DeedleFake/wdte/std/io/file"
@@ -11,4 +12,5 @@ import (
 	_ "github.com/DeedleFake/wdte/std/rand"
 	_ "github.com/DeedleFake/wdte/std/stream"
 	_ "github.com/DeedleFake/wdte/std/strings"
+	_ "github.com/DeedleFake/wdte/std/sync"
 )
@@ -0,0 +1,193 @@
+package std
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/DeedleFake/wdte"
+)
+
+var (
+	// ErrDivideByZero is returned when an integer or rational number is
+	// divided by zero.
+	ErrDivideByZero = errors.New("division by zero")
+
+	// ErrRatMod is returned when attempting to take the modulus of a
+	// rational number.
+	ErrRatMod = errors.New("modulus of a rational number")
+)
+
+// promote promotes a and b to a common numeric type, returning an
+// error if either of them is not a number.
+func promote(a, b wdte.Func) (wdte.Func, wdte.Func, error) {
+	a, b, ok := wdte.Promote(a, b)
+	if !ok {
+		return nil, nil, fmt.Errorf("Unable to perform arithmetic on %v and %v", a, b)
+	}
+	return a, b, nil
+}
+
+// ratOrInt returns r as a BigInt if it is an integer and as a BigRat
+// otherwise.
+func ratOrInt(r *big.Rat) wdte.Func {
+	if r.IsInt() {
+		return wdte.BigInt{Int: new(big.Int).Set(r.Num())}
+	}
+	return wdte.BigRat{Rat: r}
+}
+
+func add(a, b wdte.Func) (wdte.Func, error) {
+	a, b, err := promote(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	switch a := a.(type) {
+	case wdte.Int:
+		b := b.(wdte.Int)
+		r := a + b
+		if (r > a) == (b > 0) {
+			return r, nil
+		}
+		return wdte.BigInt{Int: new(big.Int).Add(big.NewInt(int64(a)), big.NewInt(int64(b)))}, nil
+
+	case wdte.BigInt:
+		return wdte.BigInt{Int: new(big.Int).Add(a.Int, b.(wdte.BigInt).Int)}, nil
+
+	case wdte.BigRat:
+		return wdte.BigRat{Rat: new(big.Rat).Add(a.Rat, b.(wdte.BigRat).Rat)}, nil
+
+	default:
+		return a.(wdte.Number) + b.(wdte.Number), nil
+	}
+}
+
+func sub(a, b wdte.Func) (wdte.Func, error) {
+	a, b, err := promote(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	switch a := a.(type) {
+	case wdte.Int:
+		b := b.(wdte.Int)
+		r := a - b
+		if (r < a) == (b > 0) {
+			return r, nil
+		}
+		return wdte.BigInt{Int: new(big.Int).Sub(big.NewInt(int64(a)), big.NewInt(int64(b)))}, nil
+
+	case wdte.BigInt:
+		return wdte.BigInt{Int: new(big.Int).Sub(a.Int, b.(wdte.BigInt).Int)}, nil
+
+	case wdte.BigRat:
+		return wdte.BigRat{Rat: new(big.Rat).Sub(a.Rat, b.(wdte.BigRat).Rat)}, nil
+
+	default:
+		return a.(wdte.Number) - b.(wdte.Number), nil
+	}
+}
+
+func mul(a, b wdte.Func) (wdte.Func, error) {
+	a, b, err := promote(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	switch a := a.(type) {
+	case wdte.Int:
+		b := b.(wdte.Int)
+		if (a == 0) || (b == 0) {
+			return wdte.Int(0), nil
+		}
+		r := a * b
+		if (r/b == a) && !((a == -1) && (b == math.MinInt64)) && !((b == -1) && (a == math.MinInt64)) {
+			return r, nil
+		}
+		return wdte.BigInt{Int: new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(int64(b)))}, nil
+
+	case wdte.BigInt:
+		return wdte.BigInt{Int: new(big.Int).Mul(a.Int, b.(wdte.BigInt).Int)}, nil
+
+	case wdte.BigRat:
+		return wdte.BigRat{Rat: new(big.Rat).Mul(a.Rat, b.(wdte.BigRat).Rat)}, nil
+
+	default:
+		return a.(wdte.Number) * b.(wdte.Number), nil
+	}
+}
+
+// div divides a by b. Integers that divide evenly result in an
+// integer of the same type, while those that don't result in a
+// BigRat.
+func div(a, b wdte.Func) (wdte.Func, error) {
+	a, b, err := promote(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	switch a := a.(type) {
+	case wdte.Int:
+		b := b.(wdte.Int)
+		if b == 0 {
+			return nil, ErrDivideByZero
+		}
+		if (a%b == 0) && !((a == math.MinInt64) && (b == -1)) {
+			return a / b, nil
+		}
+		return ratOrInt(big.NewRat(int64(a), int64(b))), nil
+
+	case wdte.BigInt:
+		b := b.(wdte.BigInt)
+		if b.Sign() == 0 {
+			return nil, ErrDivideByZero
+		}
+		return ratOrInt(new(big.Rat).SetFrac(a.Int, b.Int)), nil
+
+	case wdte.BigRat:
+		b := b.(wdte.BigRat)
+		if b.Sign() == 0 {
+			return nil, ErrDivideByZero
+		}
+		return wdte.BigRat{Rat: new(big.Rat).Quo(a.Rat, b.Rat)}, nil
+
+	default:
+		return a.(wdte.Number) / b.(wdte.Number), nil
+	}
+}
+
+// mod returns the remainder of a divided by b. For integer types, the
+// result has the same sign as a, matching Go's % operator.
+func mod(a, b wdte.Func) (wdte.Func, error) {
+	a, b, err := promote(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	switch a := a.(type) {
+	case wdte.Int:
+		b := b.(wdte.Int)
+		if b == 0 {
+			return nil, ErrDivideByZero
+		}
+		return a % b, nil
+
+	case wdte.BigInt:
+		b := b.(wdte.BigInt)
+		if b.Sign() == 0 {
+			return nil, ErrDivideByZero
+		}
+		return wdte.BigInt{Int: new(big.Int).Rem(a.Int, b.Int)}, nil
+
+	case wdte.BigRat:
+		return nil, ErrRatMod
+
+	default:
+		return wdte.Number(math.Mod(
+			float64(a.(wdte.Number)),
+			float64(b.(wdte.Number)),
+		)), nil
+	}
+}
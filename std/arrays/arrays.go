@@ -63,6 +63,10 @@ func sorter(sortFunc func(interface{}, func(int, int) bool)) (f wdte.GoFunc) {
 			if err != nil {
 				return false
 			}
+			if e, ok := frame.Cancelled(); ok {
+				err = e
+				return false
+			}
 
 			r := less.Call(frame, array[i1], array[i2])
 			if e, ok := r.(errorFunc); ok {
@@ -0,0 +1,220 @@
+// Package bytes contains functions for dealing with raw binary data.
+package bytes
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/DeedleFake/wdte"
+	"github.com/DeedleFake/wdte/std"
+	"github.com/DeedleFake/wdte/wdteutil"
+)
+
+// toBytes converts f to wdte.Bytes. Strings are converted to their
+// underlying bytes.
+func toBytes(f wdte.Func) wdte.Bytes {
+	switch f := f.(type) {
+	case wdte.Bytes:
+		return f
+	case wdte.String:
+		return wdte.Bytes(f)
+	}
+
+	panic(fmt.Errorf("Unexpected argument type: %T", f))
+}
+
+// FromString is a WDTE function with the following signature:
+//
+//    fromString s
+//
+// Returns the bytes of the string s.
+func FromString(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("fromString")
+
+	if len(args) == 0 {
+		return wdte.GoFunc(FromString)
+	}
+
+	return wdte.Bytes(args[0].(wdte.String))
+}
+
+// String is a WDTE function with the following signature:
+//
+//    string b
+//
+// Returns a string containing the bytes b. No validation of the
+// encoding of b is performed.
+func String(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("string")
+
+	if len(args) == 0 {
+		return wdte.GoFunc(String)
+	}
+
+	return wdte.String(args[0].(wdte.Bytes))
+}
+
+// Slice is a WDTE function with the following signatures:
+//
+//    slice b start end
+//    (slice end) b start
+//    (slice start end) b
+//
+// Returns the bytes of b in the range [start,end).
+func Slice(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("slice")
+
+	if len(args) < 3 {
+		return wdteutil.SaveArgsReverse(wdte.GoFunc(Slice), args...)
+	}
+
+	b := args[0].(wdte.Bytes)
+	start, err := wdte.ToInt(args[1])
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+	end, err := wdte.ToInt(args[2])
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+
+	if (start < 0) || (end > len(b)) || (start > end) {
+		return wdte.Error{
+			Err:   fmt.Errorf("slice [%v:%v] is out of range [0,%v]", start, end, len(b)),
+			Frame: frame,
+		}
+	}
+
+	return b[start:end:end]
+}
+
+// Concat is a WDTE function with the following signatures:
+//
+//    concat b ...
+//    (concat b) ...
+//
+// Returns the concatenation of all of its arguments, all of which
+// should be bytes or strings, in the order that they were given.
+func Concat(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("concat")
+
+	if len(args) < 2 {
+		return wdteutil.SaveArgs(wdte.GoFunc(Concat), args...)
+	}
+
+	var r wdte.Bytes
+	for _, arg := range args {
+		r = append(r, toBytes(arg)...)
+	}
+	return r
+}
+
+// Index is a WDTE function with the following signatures:
+//
+//    index b sep
+//    (index sep) b
+//
+// Returns the index of the first instance of sep in b, or -1 if sep
+// isn't present in b. sep may be either bytes or a string.
+func Index(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("index")
+
+	if len(args) < 2 {
+		return wdteutil.SaveArgsReverse(wdte.GoFunc(Index), args...)
+	}
+
+	b := args[0].(wdte.Bytes)
+	sep := toBytes(args[1])
+
+	return wdte.Number(bytes.Index(b, sep))
+}
+
+// Hex is a WDTE function with the following signature:
+//
+//    hex b
+//
+// Returns a string containing the hexadecimal encoding of b.
+func Hex(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("hex")
+
+	if len(args) == 0 {
+		return wdte.GoFunc(Hex)
+	}
+
+	return wdte.String(hex.EncodeToString(args[0].(wdte.Bytes)))
+}
+
+// FromHex is a WDTE function with the following signature:
+//
+//    fromHex s
+//
+// Returns the bytes represented by the hexadecimal string s.
+func FromHex(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("fromHex")
+
+	if len(args) == 0 {
+		return wdte.GoFunc(FromHex)
+	}
+
+	b, err := hex.DecodeString(string(args[0].(wdte.String)))
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+	return wdte.Bytes(b)
+}
+
+// Base64 is a WDTE function with the following signature:
+//
+//    base64 b
+//
+// Returns a string containing the standard base64 encoding of b.
+func Base64(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("base64")
+
+	if len(args) == 0 {
+		return wdte.GoFunc(Base64)
+	}
+
+	return wdte.String(base64.StdEncoding.EncodeToString(args[0].(wdte.Bytes)))
+}
+
+// FromBase64 is a WDTE function with the following signature:
+//
+//    fromBase64 s
+//
+// Returns the bytes represented by the standard base64 encoded string
+// s.
+func FromBase64(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("fromBase64")
+
+	if len(args) == 0 {
+		return wdte.GoFunc(FromBase64)
+	}
+
+	b, err := base64.StdEncoding.DecodeString(string(args[0].(wdte.String)))
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+	return wdte.Bytes(b)
+}
+
+// Scope is a scope containing the functions in this package.
+var Scope = wdte.S().Map(map[wdte.ID]wdte.Func{
+	"fromString": wdte.GoFunc(FromString),
+	"string":     wdte.GoFunc(String),
+
+	"slice":  wdte.GoFunc(Slice),
+	"concat": wdte.GoFunc(Concat),
+	"index":  wdte.GoFunc(Index),
+
+	"hex":        wdte.GoFunc(Hex),
+	"fromHex":    wdte.GoFunc(FromHex),
+	"base64":     wdte.GoFunc(Base64),
+	"fromBase64": wdte.GoFunc(FromBase64),
+})
+
+func init() {
+	std.Register("bytes", Scope)
+}
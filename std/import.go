@@ -2,6 +2,8 @@ package std
 
 import (
 	"fmt"
+	"os"
+	"sync"
 
 	"github.com/DeedleFake/wdte"
 )
@@ -11,18 +13,40 @@ var (
 	// modules.
 	Import = wdte.ImportFunc(stdImporter)
 
-	modules = make(map[string]*wdte.Scope)
+	modulesM sync.RWMutex
+	modules  = make(map[string]*wdte.Scope)
 )
 
 func stdImporter(from string) (*wdte.Scope, error) {
-	if m, ok := modules[from]; ok {
+	modulesM.RLock()
+	m, ok := modules[from]
+	modulesM.RUnlock()
+	if ok {
 		return m, nil
 	}
 
-	return nil, fmt.Errorf("Unknown import: %q", from)
+	return nil, UnknownImportError(from)
 }
 
-// Register registers a module for importing by Import.
+// UnknownImportError is returned by Import when asked to import a
+// module that hasn't been registered. For the sake of importers that
+// try several sources in turn, it is considered equivalent to
+// os.ErrNotExist by errors.Is.
+type UnknownImportError string
+
+func (err UnknownImportError) Error() string {
+	return fmt.Sprintf("Unknown import: %q", string(err))
+}
+
+func (err UnknownImportError) Is(target error) bool {
+	return target == os.ErrNotExist
+}
+
+// Register registers a module for importing by Import. It is safe to
+// call Register concurrently with itself and with Import.
 func Register(name string, module *wdte.Scope) {
+	modulesM.Lock()
+	defer modulesM.Unlock()
+
 	modules[name] = module
 }
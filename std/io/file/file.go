@@ -2,8 +2,11 @@
 package file
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
 
 	"github.com/DeedleFake/wdte"
 	"github.com/DeedleFake/wdte/std"
@@ -110,6 +113,45 @@ var Scope = wdte.S().Map(map[wdte.ID]wdte.Func{
 	"append": wdte.GoFunc(Append),
 })
 
+// ErrReadOnly is returned by the functions in a scope returned by
+// Restricted when attempting to open a file for writing if the scope
+// is read-only.
+var ErrReadOnly = errors.New("files are read-only")
+
+// Restricted returns a variant of Scope that only has access to files
+// inside of the directory root. Paths given to its functions are
+// treated as slash-separated paths relative to root, even if they are
+// absolute, and can't refer to files outside of it. Note that symbolic
+// links inside of root are followed, however, and may lead outside of
+// it.
+//
+// If readOnly is true, create and append return ErrReadOnly instead
+// of opening files.
+func Restricted(root string, readOnly bool) *wdte.Scope {
+	restrict := func(f wdte.GoFunc, write bool) wdte.Func {
+		var r wdte.GoFunc
+		r = func(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+			if len(args) == 0 {
+				return r
+			}
+
+			if write && readOnly {
+				return wdte.Error{Err: ErrReadOnly, Frame: frame}
+			}
+
+			p := path.Clean("/" + string(args[0].(wdte.String)))
+			return f(frame, wdte.String(filepath.Join(root, filepath.FromSlash(p))))
+		}
+		return r
+	}
+
+	return wdte.S().Map(map[wdte.ID]wdte.Func{
+		"open":   restrict(Open, false),
+		"create": restrict(Create, true),
+		"append": restrict(Append, true),
+	})
+}
+
 func init() {
 	std.Register("io/file", Scope)
 }
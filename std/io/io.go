@@ -161,8 +161,14 @@ func Seek(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	}
 
 	s := args[0].(io.Seeker)
-	off := int64(args[1].(wdte.Number))
-	rel := args[2].(wdte.Number)
+	off, err := wdte.ToInt(args[1])
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+	rel, err := wdte.ToFloat(args[2])
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
 
 	var w int
 	switch {
@@ -174,7 +180,7 @@ func Seek(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 		w = io.SeekStart
 	}
 
-	_, err := s.Seek(off, w)
+	_, err = s.Seek(int64(off), w)
 	if err != nil {
 		return wdte.Error{Err: err, Frame: frame}
 	}
@@ -280,7 +286,7 @@ func Copy(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 		a1 = w
 	}
 
-	_, err := io.Copy(w, r)
+	_, err := io.Copy(w, contextReader{frame: frame, r: r})
 	if err != nil {
 		return wdte.Error{Err: err, Frame: frame}
 	}
@@ -303,13 +309,87 @@ func String(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	r := args[0].(reader)
 
 	var buf bytes.Buffer
-	_, err := io.Copy(&buf, r)
+	_, err := io.Copy(&buf, contextReader{frame: frame, r: r})
 	if err != nil {
 		return wdte.Error{Err: err, Frame: frame}
 	}
 	return wdte.String(buf.String())
 }
 
+// Bytes is a WDTE function with the following signature:
+//
+//    bytes r
+//
+// Reads the entirety of the reader r and returns the result as
+// wdte.Bytes. Unlike String, no assumptions are made about the
+// encoding of the data read.
+func Bytes(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("bytes")
+
+	if len(args) == 0 {
+		return wdte.GoFunc(Bytes)
+	}
+
+	r := args[0].(reader)
+
+	var buf bytes.Buffer
+	_, err := io.Copy(&buf, contextReader{frame: frame, r: r})
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+	return wdte.Bytes(buf.Bytes())
+}
+
+// Read is a WDTE function with the following signatures:
+//
+//    read r n
+//    (read n) r
+//
+// Reads up to n bytes from the reader r, returning them as
+// wdte.Bytes. It blocks until either n bytes have been read or the
+// reader yields EOF, so fewer than n bytes are only returned at the
+// end of the data. If no bytes could be read because the reader is at
+// EOF, an empty wdte.Bytes is returned.
+func Read(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("read")
+
+	if len(args) < 2 {
+		return wdteutil.SaveArgsReverse(wdte.GoFunc(Read), args...)
+	}
+
+	r := args[0].(reader)
+	n, err := wdte.ToInt(args[1])
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+	if n < 0 {
+		return wdte.Error{Err: fmt.Errorf("negative read size: %v", n), Frame: frame}
+	}
+
+	buf := make([]byte, n)
+	n, err = io.ReadFull(contextReader{frame: frame, r: r}, buf)
+	if (err != nil) && (err != io.EOF) && (err != io.ErrUnexpectedEOF) {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+	return wdte.Bytes(buf[:n])
+}
+
+// contextReader wraps an io.Reader, failing reads once the context of
+// frame has been cancelled. This allows functions that read in a loop
+// to be stopped between reads.
+type contextReader struct {
+	frame wdte.Frame
+	r     io.Reader
+}
+
+func (r contextReader) Read(buf []byte) (int, error) {
+	if err, ok := r.frame.Cancelled(); ok {
+		return 0, err
+	}
+
+	return r.r.Read(buf)
+}
+
 // scanner is a simple wrapper that allows a bufio.Scanner to be used
 // as a stream.Stream.
 type scanner struct {
@@ -321,6 +401,10 @@ func (s scanner) Call(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 }
 
 func (s scanner) Next(frame wdte.Frame) (wdte.Func, bool) {
+	if err, ok := frame.Cancelled(); ok {
+		return err, true
+	}
+
 	ok := s.s.Scan()
 	if !ok {
 		err := s.s.Err()
@@ -436,6 +520,10 @@ func (r runeStream) Call(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 }
 
 func (r runeStream) Next(frame wdte.Frame) (wdte.Func, bool) {
+	if err, ok := frame.Cancelled(); ok {
+		return err, true
+	}
+
 	c, _, err := r.r.ReadRune()
 	if err != nil {
 		if err == io.EOF {
@@ -514,13 +602,19 @@ func write(f func(io.Writer, interface{}) error) (gf wdte.Func) {
 //    (write d) w
 //
 // It writes the data d to the writer w in much the same way that Go's
-// fmt.Fprint does. It returns w to allow for easier chaining.
+// fmt.Fprint does. If d is wdte.Bytes, it is written to w as is. It
+// returns w to allow for easier chaining.
 //
 // If both arguments are writers, it will consider either the first
 // argument or the outer argument to be w.
 func Write(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	frame = frame.Sub("write")
 	return write(func(w io.Writer, v interface{}) error {
+		if b, ok := v.(wdte.Bytes); ok {
+			_, err := w.Write(b)
+			return err
+		}
+
 		_, err := fmt.Fprint(w, v)
 		return err
 	}).Call(frame, args...)
@@ -534,13 +628,19 @@ func Write(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 //    (writeln d) w
 //
 // It writes the data d to the writer w in much the same way that Go's
-// fmt.Fprintln does. It returns w to allow for easier chaining.
+// fmt.Fprintln does. If d is wdte.Bytes, it is written to w as is,
+// followed by a newline. It returns w to allow for easier chaining.
 //
 // If both arguments are writers, it will consider either the first
 // argument or the outer argument to be w.
 func Writeln(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	frame = frame.Sub("writeln")
 	return write(func(w io.Writer, v interface{}) error {
+		if b, ok := v.(wdte.Bytes); ok {
+			_, err := w.Write(append(b[:len(b):len(b)], '\n'))
+			return err
+		}
+
 		_, err := fmt.Fprintln(w, v)
 		return err
 	}).Call(frame, args...)
@@ -620,6 +720,8 @@ var Scope = wdte.S().Map(map[wdte.ID]wdte.Func{
 	"copy":    wdte.GoFunc(Copy),
 
 	"string": wdte.GoFunc(String),
+	"bytes":  wdte.GoFunc(Bytes),
+	"read":   wdte.GoFunc(Read),
 	"lines":  wdte.GoFunc(Lines),
 	"words":  wdte.GoFunc(Words),
 	"scan":   wdte.GoFunc(Scan),
@@ -631,6 +733,26 @@ var Scope = wdte.S().Map(map[wdte.ID]wdte.Func{
 	//"panicln": wdte.GoFunc(Panicln),
 })
 
+// NoStdin is a variant of Scope without stdin. It is intended for
+// use by clients that provide the io module to scripts that shouldn't
+// be able to read from the process's standard input, such as via
+// importer.Sandbox.
+var NoStdin = wdte.S().Custom(
+	func(id wdte.ID) wdte.Func {
+		if id == "stdin" {
+			return nil
+		}
+		return Scope.Get(id)
+	},
+	func(known map[wdte.ID]struct{}) {
+		for _, id := range Scope.Known() {
+			if id != "stdin" {
+				known[id] = struct{}{}
+			}
+		}
+	},
+)
+
 func init() {
 	std.Register("io", Scope)
 }
@@ -29,8 +29,11 @@ func Sin(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 
 	frame = frame.Sub("sin")
 
-	a := args[0].(wdte.Number)
-	return wdte.Number(math.Sin(float64(a)))
+	a, err := wdte.ToFloat(args[0])
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+	return wdte.Number(math.Sin(a))
 }
 
 // Cos is a WDTE function with the following signature:
@@ -45,8 +48,11 @@ func Cos(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 
 	frame = frame.Sub("cos")
 
-	a := args[0].(wdte.Number)
-	return wdte.Number(math.Cos(float64(a)))
+	a, err := wdte.ToFloat(args[0])
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+	return wdte.Number(math.Cos(a))
 }
 
 // Tan is a WDTE function with the following signature:
@@ -61,8 +67,11 @@ func Tan(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 
 	frame = frame.Sub("tan")
 
-	a := args[0].(wdte.Number)
-	return wdte.Number(math.Tan(float64(a)))
+	a, err := wdte.ToFloat(args[0])
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+	return wdte.Number(math.Tan(a))
 }
 
 // Floor is a WDTE function with the following signature:
@@ -77,8 +86,11 @@ func Floor(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 
 	frame = frame.Sub("floor")
 
-	a := args[0].(wdte.Number)
-	return wdte.Number(math.Floor(float64(a)))
+	a, err := wdte.ToFloat(args[0])
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+	return wdte.Number(math.Floor(a))
 }
 
 // Ceil is a WDTE function with the following signature:
@@ -93,8 +105,11 @@ func Ceil(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 
 	frame = frame.Sub("ceil")
 
-	a := args[0].(wdte.Number)
-	return wdte.Number(math.Ceil(float64(a)))
+	a, err := wdte.ToFloat(args[0])
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+	return wdte.Number(math.Ceil(a))
 }
 
 // Abs is a WDTE function with the following signature:
@@ -109,8 +124,11 @@ func Abs(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 
 	frame = frame.Sub("abs")
 
-	a := args[0].(wdte.Number)
-	return wdte.Number(math.Abs(float64(a)))
+	a, err := wdte.ToFloat(args[0])
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+	return wdte.Number(math.Abs(a))
 }
 
 // Scope is a scope that contains the functions in this package.
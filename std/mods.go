@@ -1,16 +1,21 @@
 package std
 
 import (
+	"sync"
+
 	"github.com/DeedleFake/wdte"
 	"github.com/DeedleFake/wdte/wdteutil"
 )
 
 // A Memo wraps another function, caching the results of calls with
-// the same arguments.
+// the same arguments. It is safe to call a Memo from multiple
+// goroutines at once, though concurrent calls with the same arguments
+// may each call the wrapped function before either result is cached.
 type Memo struct {
 	Func wdte.Func
 	Args []wdte.ID
 
+	m     sync.Mutex
 	cache memoCache
 }
 
@@ -22,19 +27,45 @@ func (m *Memo) Call(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 		check = append(check, s.Get(id).Call(frame))
 	}
 
+	m.m.Lock()
 	cached, ok := m.cache.Get(check)
+	m.m.Unlock()
 	if ok {
 		return cached
 	}
 
+	// The lock can't be held while calling the wrapped function, as it
+	// is likely to recursively call this Memo.
 	r := m.Func.Call(frame, check...)
+
+	m.m.Lock()
 	m.cache.Set(check, r)
+	m.m.Unlock()
+
 	return r
 }
 
+// memoCache is a tree of cached results keyed by argument. Each level
+// of the tree stores its children in buckets keyed by wdte.Hash, with
+// wdte.Equal being used to find the right entry inside of a bucket.
 type memoCache struct {
 	val  wdte.Func
-	next map[wdte.Func]*memoCache
+	next map[uint64][]memoEntry
+}
+
+type memoEntry struct {
+	key   wdte.Func
+	cache *memoCache
+}
+
+func (cache *memoCache) find(key wdte.Func, h uint64) *memoCache {
+	for _, e := range cache.next[h] {
+		if wdte.Equal(e.key, key) {
+			return e.cache
+		}
+	}
+
+	return nil
 }
 
 func (cache *memoCache) Get(args []wdte.Func) (wdte.Func, bool) {
@@ -46,11 +77,7 @@ func (cache *memoCache) Get(args []wdte.Func) (wdte.Func, bool) {
 		return cache.val, true
 	}
 
-	if cache.next == nil {
-		return nil, false
-	}
-
-	return cache.next[args[0]].Get(args[1:])
+	return cache.find(args[0], wdte.Hash(args[0])).Get(args[1:])
 }
 
 func (cache *memoCache) Set(args []wdte.Func, val wdte.Func) {
@@ -60,12 +87,16 @@ func (cache *memoCache) Set(args []wdte.Func, val wdte.Func) {
 	}
 
 	if cache.next == nil {
-		cache.next = make(map[wdte.Func]*memoCache)
+		cache.next = make(map[uint64][]memoEntry)
 	}
 
-	n := new(memoCache)
+	h := wdte.Hash(args[0])
+	n := cache.find(args[0], h)
+	if n == nil {
+		n = new(memoCache)
+		cache.next[h] = append(cache.next[h], memoEntry{key: args[0], cache: n})
+	}
 	n.Set(args[1:], val)
-	cache.next[args[0]] = n
 }
 
 func ModMemo(frame wdte.Frame, args ...wdte.Func) wdte.Func {
@@ -6,6 +6,7 @@ import (
 	crand "crypto/rand"
 	"encoding/binary"
 	"math/rand"
+	"sync"
 
 	"github.com/DeedleFake/wdte"
 	"github.com/DeedleFake/wdte/std"
@@ -39,7 +40,10 @@ func Next(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	return r.Next()
 }
 
+// source is the Source returned by gen and ugen. A *rand.Rand is not
+// safe for concurrent use, so access to it is guarded by m.
 type source struct {
+	m    sync.Mutex
 	rand *rand.Rand
 }
 
@@ -48,6 +52,9 @@ func (s *source) Call(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 }
 
 func (s *source) Next() wdte.Number {
+	s.m.Lock()
+	defer s.m.Unlock()
+
 	return wdte.Number(s.rand.Float64())
 }
 
@@ -71,7 +78,10 @@ func Gen(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 		return wdte.GoFunc(Gen)
 	}
 
-	seed := args[0].(wdte.Number)
+	seed, err := wdte.ToFloat(args[0])
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
 	return &source{rand: rand.New(rand.NewSource(int64(seed)))}
 }
 
@@ -113,7 +123,10 @@ func Stream(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	}
 
 	r := args[0].(Source)
-	rem := int(args[1].(wdte.Number))
+	rem, err := wdte.ToInt(args[1])
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
 
 	return stream.NextFunc(func(frame wdte.Frame) (wdte.Func, bool) {
 		if rem <= 0 {
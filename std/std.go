@@ -2,7 +2,6 @@ package std
 
 import (
 	"fmt"
-	"math"
 
 	"github.com/DeedleFake/wdte"
 	"github.com/DeedleFake/wdte/wdteutil"
@@ -13,22 +12,17 @@ import (
 //    + a ...
 //    (+ a) ...
 //
-// Returns the sum of a and the rest of its arguments.
+// Returns the sum of a and the rest of its arguments. Arguments of
+// differing numeric types are promoted using wdte.Promote, so, for
+// example, adding an Int to a Number results in a Number. Ints that
+// overflow are promoted to BigInts.
 func Plus(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	if len(args) <= 1 {
 		return wdteutil.SaveArgsReverse(wdte.GoFunc(Plus), args...)
 	}
 
 	frame = frame.Sub("+")
-
-	var sum wdte.Number
-	for _, arg := range args {
-		if _, ok := arg.(error); ok {
-			return arg
-		}
-		sum += arg.(wdte.Number)
-	}
-	return sum
+	return fold(frame, add, args)
 }
 
 // Minus is a WDTE with the following signatures:
@@ -36,25 +30,15 @@ func Plus(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 //    - a b
 //    (- b) a
 //
-// Returns a minus b.
+// Returns a minus b. Numeric types are handled the same way as in
+// Plus.
 func Minus(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	if len(args) <= 1 {
 		return wdteutil.SaveArgsReverse(wdte.GoFunc(Minus), args...)
 	}
 
 	frame = frame.Sub("-")
-
-	a1 := args[0]
-	if _, ok := a1.(error); ok {
-		return a1
-	}
-
-	a2 := args[1]
-	if _, ok := a2.(error); ok {
-		return a2
-	}
-
-	return a1.(wdte.Number) - a2.(wdte.Number)
+	return fold(frame, sub, args[:2])
 }
 
 // Times is a WDTE function with the following signatures:
@@ -62,22 +46,15 @@ func Minus(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 //    * a ...
 //    (* a) ...
 //
-// Returns the product of a and its other arguments.
+// Returns the product of a and its other arguments. Numeric types are
+// handled the same way as in Plus.
 func Times(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	if len(args) <= 1 {
 		return wdteutil.SaveArgsReverse(wdte.GoFunc(Times), args...)
 	}
 
 	frame = frame.Sub("*")
-
-	p := wdte.Number(1)
-	for _, arg := range args {
-		if _, ok := arg.(error); ok {
-			return arg
-		}
-		p *= arg.(wdte.Number)
-	}
-	return p
+	return fold(frame, mul, args)
 }
 
 // Div is a WDTE function with the following signatures:
@@ -85,25 +62,17 @@ func Times(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 //    / a b
 //    (/ b) a
 //
-// Returns a divided by b.
+// Returns a divided by b. Numeric types are handled the same way as
+// in Plus, with the exception that dividing two integers that don't
+// divide evenly results in a BigRat. Dividing an integer or a BigRat
+// by zero results in an error.
 func Div(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	if len(args) <= 1 {
 		return wdteutil.SaveArgsReverse(wdte.GoFunc(Div), args...)
 	}
 
 	frame = frame.Sub("/")
-
-	a1 := args[0]
-	if _, ok := a1.(error); ok {
-		return a1
-	}
-
-	a2 := args[1]
-	if _, ok := a2.(error); ok {
-		return a2
-	}
-
-	return a1.(wdte.Number) / a2.(wdte.Number)
+	return fold(frame, div, args[:2])
 }
 
 // Mod is a WDTE function with the following signatures:
@@ -111,28 +80,38 @@ func Div(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 //    % a b
 //    (% b) a
 //
-// Returns a mod b.
+// Returns a mod b. Numeric types are handled the same way as in Plus.
+// Taking the modulus of a BigRat results in an error.
 func Mod(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	if len(args) <= 1 {
 		return wdteutil.SaveArgsReverse(wdte.GoFunc(Mod), args...)
 	}
 
 	frame = frame.Sub("%")
+	return fold(frame, mod, args[:2])
+}
 
-	a1 := args[0]
-	if _, ok := a1.(error); ok {
-		return a1
+// fold applies op to args from left to right, returning the result.
+// If any of the arguments are errors, the first one is returned.
+func fold(frame wdte.Frame, op func(a, b wdte.Func) (wdte.Func, error), args []wdte.Func) wdte.Func {
+	for _, arg := range args {
+		if _, ok := arg.(error); ok {
+			return arg
+		}
 	}
 
-	a2 := args[1]
-	if _, ok := a2.(error); ok {
-		return a2
+	r := args[0]
+	for _, arg := range args[1:] {
+		var err error
+		r, err = op(r, arg)
+		if err != nil {
+			return wdte.Error{
+				Err:   err,
+				Frame: frame,
+			}
+		}
 	}
-
-	return wdte.Number(math.Mod(
-		float64(a1.(wdte.Number)),
-		float64(a2.(wdte.Number)),
-	))
+	return r
 }
 
 // Equals is a WDTE function with the following signatures:
@@ -140,10 +119,10 @@ func Mod(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 //    == a b
 //    (== b) a
 //
-// Returns true if a equals b. If a implements wdte.Comparer, the
-// equality check is done using that implementation. If a does not but
-// b does, b's implementation is used. If neither does, a direct Go
-// equality check is used.
+// Returns true if a equals b. The check is done using wdte.Equal, so
+// if a implements wdte.Comparer, the equality check is done using that
+// implementation. If a does not but b does, b's implementation is
+// used. If neither does, a direct Go equality check is used.
 func Equals(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	if len(args) <= 1 {
 		return wdteutil.SaveArgsReverse(wdte.GoFunc(Equals), args...)
@@ -159,17 +138,7 @@ func Equals(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 		return a2
 	}
 
-	if cmp, ok := a1.(wdte.Comparer); ok {
-		c, _ := cmp.Compare(a2)
-		return wdte.Bool(c == 0)
-	}
-
-	if cmp, ok := a2.(wdte.Comparer); ok {
-		c, _ := cmp.Compare(a1)
-		return wdte.Bool(c == 0)
-	}
-
-	return wdte.Bool(a1 == a2)
+	return wdte.Bool(wdte.Equal(a1, a2))
 }
 
 // Less is a WDTE function with the following signatures:
@@ -49,6 +49,10 @@ func Collect(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 
 	r := wdte.Array{}
 	for {
+		if err, ok := frame.Cancelled(); ok {
+			return err
+		}
+
 		n, ok := a.Next(frame)
 		if !ok {
 			break
@@ -84,6 +88,10 @@ func Drain(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 
 	last := End()
 	for {
+		if err, ok := frame.Cancelled(); ok {
+			return err
+		}
+
 		n, ok := s.Next(frame)
 		if !ok {
 			return last
@@ -129,6 +137,10 @@ func Reduce(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	r := args[2]
 
 	for {
+		if err, ok := frame.Cancelled(); ok {
+			return err
+		}
+
 		n, ok := s.Next(frame)
 		if !ok {
 			return cur
@@ -192,15 +204,18 @@ func Extent(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	}
 
 	s := args[0].(Stream)
-	length := args[1].(wdte.Number)
+	length, err := wdte.ToInt(args[1])
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
 	less := args[2]
 
 	var c func(wdte.Array, int, wdte.Func) wdte.Array
 	c = func(extent wdte.Array, i int, f wdte.Func) wdte.Array {
 		extent = append(extent[:i], append(wdte.Array{f}, extent[i:]...)...)
 
-		if (length >= 0) && (len(extent) >= int(length)) {
-			extent = extent[:int(length)]
+		if (length >= 0) && (len(extent) >= length) {
+			extent = extent[:length]
 
 			c = func(extent wdte.Array, i int, f wdte.Func) wdte.Array {
 				copy(extent[i+1:], extent[i:])
@@ -212,13 +227,17 @@ func Extent(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 		return extent
 	}
 
-	sc := int(length)
+	sc := length
 	if sc < 0 {
 		sc = 0
 	}
 
 	extent := make(wdte.Array, 0, sc)
 	for {
+		if err, ok := frame.Cancelled(); ok {
+			return err
+		}
+
 		n, ok := s.Next(frame)
 		if !ok {
 			return extent
@@ -296,6 +315,10 @@ func Any(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	f := args[1]
 
 	for {
+		if err, ok := frame.Cancelled(); ok {
+			return err
+		}
+
 		n, ok := s.Next(frame)
 		if !ok {
 			return wdte.Bool(false)
@@ -326,6 +349,10 @@ func All(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	f := args[1]
 
 	for {
+		if err, ok := frame.Cancelled(); ok {
+			return err
+		}
+
 		n, ok := s.Next(frame)
 		if !ok {
 			return wdte.Bool(true)
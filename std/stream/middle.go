@@ -1,6 +1,10 @@
 package stream
 
 import (
+	"context"
+	"fmt"
+	"sync"
+
 	"github.com/DeedleFake/wdte"
 	"github.com/DeedleFake/wdte/wdteutil"
 )
@@ -62,6 +66,10 @@ func Filter(frame wdte.Frame, args ...wdte.Func) (filter wdte.Func) {
 
 		return NextFunc(func(frame wdte.Frame) (wdte.Func, bool) {
 			for {
+				if err, ok := frame.Cancelled(); ok {
+					return err, true
+				}
+
 				n, ok := s.Next(frame)
 				if !ok {
 					return nil, false
@@ -107,6 +115,10 @@ func FlatMap(frame wdte.Frame, args ...wdte.Func) (mapper wdte.Func) {
 		var cur Stream
 		return NextFunc(func(frame wdte.Frame) (wdte.Func, bool) {
 			for {
+				if err, ok := frame.Cancelled(); ok {
+					return err, true
+				}
+
 				if cur != nil {
 					n, ok := cur.Next(frame)
 					if ok {
@@ -230,7 +242,10 @@ func Limit(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 		return wdte.GoFunc(Limit)
 	}
 
-	n := args[0].(wdte.Number)
+	n, err := wdte.ToInt(args[0])
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
 
 	return wdte.GoFunc(func(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 		frame = frame.Sub("limit")
@@ -264,7 +279,10 @@ func Skip(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 		return wdte.GoFunc(Skip)
 	}
 
-	n := args[0].(wdte.Number)
+	n, err := wdte.ToInt(args[0])
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
 
 	return wdte.GoFunc(func(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 		frame = frame.Sub("skip")
@@ -275,6 +293,10 @@ func Skip(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 			frame = frame.Sub("skip")
 
 			for n > 0 {
+				if err, ok := frame.Cancelled(); ok {
+					return err, true
+				}
+
 				next, ok := s.Next(frame)
 				if !ok {
 					return next, ok
@@ -337,3 +359,295 @@ func Zip(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 		return r, more
 	})
 }
+
+// PMap is a WDTE function with the following signatures:
+//
+//    (pmap n f) s
+//    ((pmap n) f) s
+//
+// It's identical to Map except that calls to f are run concurrently
+// on up to n goroutines at a time. The values are yielded in the same
+// order as the elements of s that they were produced from. Elements
+// are only read from s when the returned Stream is asked for its next
+// element, at which point up to n of them are read ahead so that the
+// calls to f can run concurrently. If the returned Stream is
+// abandoned before it ends, the calls already in progress are allowed
+// to finish, but no more elements are read.
+//
+// If a call to f returns an error, the error is yielded, after which
+// the Stream ends. Any other calls in progress are cancelled and
+// their results are discarded.
+func PMap(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	return parallelFunc(PMap, true, mapResult)(frame, args...)
+}
+
+// PMapUnordered is a WDTE function with the following signatures:
+//
+//    (pmapUnordered n f) s
+//    ((pmapUnordered n) f) s
+//
+// It's identical to PMap except that values are yielded as soon as
+// they are produced, regardless of the order of the elements of s.
+func PMapUnordered(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	return parallelFunc(PMapUnordered, false, mapResult)(frame, args...)
+}
+
+// PFilter is a WDTE function with the following signatures:
+//
+//    (pfilter n f) s
+//    ((pfilter n) f) s
+//
+// It's identical to Filter except that calls to f are run
+// concurrently on up to n goroutines at a time. Order, backpressure,
+// and errors are handled in the same way as PMap.
+func PFilter(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	return parallelFunc(PFilter, true, filterResult)(frame, args...)
+}
+
+// PFilterUnordered is a WDTE function with the following signatures:
+//
+//    (pfilterUnordered n f) s
+//    ((pfilterUnordered n) f) s
+//
+// It's identical to PFilter except that values are yielded as soon as
+// they have passed the filter, regardless of the order of the
+// elements of s.
+func PFilterUnordered(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	return parallelFunc(PFilterUnordered, false, filterResult)(frame, args...)
+}
+
+// A result is the result of processing a single element of a
+// parallel Stream.
+type result struct {
+	v    wdte.Func
+	keep bool
+}
+
+func mapResult(v, r wdte.Func) result {
+	return result{v: r, keep: true}
+}
+
+func filterResult(v, r wdte.Func) result {
+	if _, ok := r.(error); ok {
+		return result{v: r, keep: true}
+	}
+
+	return result{v: v, keep: r == wdte.Bool(true)}
+}
+
+// parallelFunc returns the implementation of one of the parallel
+// middle functions. self is the function being implemented, and
+// handle converts an element and the result of calling f on it into
+// a result.
+func parallelFunc(self wdte.GoFunc, ordered bool, handle func(v, r wdte.Func) result) wdte.GoFunc {
+	return func(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+		if len(args) < 2 {
+			return wdteutil.SaveArgs(self, args...)
+		}
+
+		n, err := wdte.ToInt(args[0])
+		if err != nil {
+			return wdte.Error{Err: err, Frame: frame}
+		}
+		if n < 1 {
+			n = 1
+		}
+		f := args[1]
+
+		var p wdte.GoFunc
+		p = func(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+			if len(args) == 0 {
+				return p
+			}
+
+			return newParallel(frame, n, ordered, args[0].(Stream), func(frame wdte.Frame, v wdte.Func) result {
+				return handle(v, callRecover(frame, f, v))
+			})
+		}
+		return p
+	}
+}
+
+// callRecover calls f with v, converting panics into errors so that
+// they don't bring down the program from a worker goroutine.
+func callRecover(frame wdte.Frame, f, v wdte.Func) (r wdte.Func) {
+	defer func() {
+		if p := recover(); p != nil {
+			err, ok := p.(error)
+			if !ok {
+				err = fmt.Errorf("%v", p)
+			}
+			r = wdte.Error{Err: err, Frame: frame}
+		}
+	}()
+
+	return f.Call(frame, v)
+}
+
+// newParallel returns a Stream which processes the elements of s
+// using up to n goroutines at a time. Elements are only read from s
+// when the Stream is asked for its next element, at which point
+// enough are read to keep n calls to work in progress. Each call
+// sends its result on a buffered channel, so the goroutines running
+// them never block, and they exit as soon as work returns, even if
+// the Stream is abandoned.
+//
+// The calls are made with a context derived from the context of
+// frame which is cancelled as soon as a call returns an error or the
+// Stream ends.
+func newParallel(frame wdte.Frame, n int, ordered bool, s Stream, work func(wdte.Frame, wdte.Func) result) Stream {
+	ctx, cancel := context.WithCancel(frame.Context())
+	p := &parallel{
+		n:       n,
+		ordered: ordered,
+		s:       s,
+		work:    work,
+		ctx:     ctx,
+		cancel:  cancel,
+		out:     make(chan result, n),
+	}
+	return NextFunc(p.Next)
+}
+
+// parallel is the state of a Stream returned by newParallel.
+type parallel struct {
+	n       int
+	ordered bool
+	s       Stream
+	work    func(wdte.Frame, wdte.Func) result
+
+	// ctx is the context that calls to work are made with. It is
+	// cancelled by fail and when the Stream ends.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// err is the first error returned by a call to work, or yielded by
+	// s. It is yielded in place of any later errors, which may just be
+	// the result of calls being cancelled because of it.
+	m   sync.Mutex
+	err wdte.Func
+
+	// pending holds a channel for each call in progress, in the order
+	// that the calls were started. It is only used if ordered is true.
+	pending []chan result
+
+	// out receives the results of calls as they finish. It is only
+	// used if ordered is false.
+	out chan result
+
+	// running is the number of calls whose results haven't been
+	// received yet.
+	running int
+
+	eof, done bool
+}
+
+func (p *parallel) Next(frame wdte.Frame) (wdte.Func, bool) {
+	if p.done {
+		return nil, false
+	}
+
+	frame = frame.Sub("parallel")
+	for {
+		for !p.eof && (p.running < p.n) {
+			v, ok := p.s.Next(frame)
+			if !ok {
+				p.eof = true
+				break
+			}
+			p.start(frame, v)
+		}
+
+		if p.running == 0 {
+			p.finish()
+			return nil, false
+		}
+
+		res, err := p.result(frame)
+		if err != nil {
+			p.finish()
+			return err, true
+		}
+		if _, ok := res.v.(error); ok {
+			p.finish()
+			return p.firstErr(), true
+		}
+		if res.keep {
+			return res.v, true
+		}
+	}
+}
+
+// start starts a call to work with v. Errors yielded by s are passed
+// through as results without calling work.
+func (p *parallel) start(frame wdte.Frame, v wdte.Func) {
+	r := p.out
+	if p.ordered {
+		r = make(chan result, 1)
+		p.pending = append(p.pending, r)
+	}
+	p.running++
+
+	if _, ok := v.(error); ok {
+		p.fail(v)
+		r <- result{v: v, keep: true}
+		return
+	}
+
+	frame = frame.WithContext(p.ctx)
+	go func() {
+		res := p.work(frame, v)
+		if _, ok := res.v.(error); ok {
+			p.fail(res.v)
+		}
+		r <- res
+	}()
+}
+
+// fail records err as the reason that the Stream is ending, unless
+// an error has already been recorded, and cancels any calls in
+// progress.
+func (p *parallel) fail(err wdte.Func) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	if p.err == nil {
+		p.err = err
+	}
+	p.cancel()
+}
+
+func (p *parallel) firstErr() wdte.Func {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	return p.err
+}
+
+// finish marks the Stream as having ended and cancels any calls still
+// in progress.
+func (p *parallel) finish() {
+	p.done = true
+	p.cancel()
+}
+
+// result waits for the next result, returning an error if the
+// context of frame is cancelled first.
+func (p *parallel) result(frame wdte.Frame) (result, wdte.Func) {
+	r := p.out
+	if p.ordered {
+		r = p.pending[0]
+	}
+
+	select {
+	case res := <-r:
+		p.running--
+		if p.ordered {
+			p.pending = p.pending[1:]
+		}
+		return res, nil
+
+	case <-frame.Context().Done():
+		return result{}, wdte.Error{Err: frame.Context().Err(), Frame: frame}
+	}
+}
@@ -63,26 +63,39 @@ func Range(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 		return wdte.GoFunc(Range)
 	}
 
+	if len(args) > 3 {
+		args = args[:3]
+	}
+
+	nums := make([]wdte.Number, 0, len(args))
+	for _, arg := range args {
+		n, err := wdte.ToFloat(arg)
+		if err != nil {
+			return wdte.Error{Err: err, Frame: frame}
+		}
+		nums = append(nums, wdte.Number(n))
+	}
+
 	// Current index, minimum/maximum value, and step.
 	var i, m wdte.Number
 	s := wdte.Number(1)
 
-	switch len(args) {
+	switch len(nums) {
 	case 1:
-		m = args[0].(wdte.Number)
+		m = nums[0]
 
 	case 2:
-		i = args[0].(wdte.Number)
-		m = args[1].(wdte.Number)
+		i = nums[0]
+		m = nums[1]
 
 		if i > m {
 			s = -1
 		}
 
 	default:
-		i = args[0].(wdte.Number)
-		m = args[1].(wdte.Number)
-		s = args[2].(wdte.Number)
+		i = nums[0]
+		m = nums[1]
+		s = nums[2]
 	}
 
 	return NextFunc(func(frame wdte.Frame) (wdte.Func, bool) {
@@ -52,6 +52,11 @@ var Scope = wdte.S().Map(map[wdte.ID]wdte.Func{
 	"skip":      wdte.GoFunc(Skip),
 	"zip":       wdte.GoFunc(Zip),
 
+	"pmap":             wdte.GoFunc(PMap),
+	"pmapUnordered":    wdte.GoFunc(PMapUnordered),
+	"pfilter":          wdte.GoFunc(PFilter),
+	"pfilterUnordered": wdte.GoFunc(PFilterUnordered),
+
 	"end":     End(),
 	"collect": wdte.GoFunc(Collect),
 	"drain":   wdte.GoFunc(Drain),
@@ -63,6 +68,35 @@ var Scope = wdte.S().Map(map[wdte.ID]wdte.Func{
 	"all": wdte.GoFunc(All),
 })
 
+// parallelIDs contains the IDs of the functions in Scope that start
+// goroutines.
+var parallelIDs = map[wdte.ID]struct{}{
+	"pmap":             {},
+	"pmapUnordered":    {},
+	"pfilter":          {},
+	"pfilterUnordered": {},
+}
+
+// Sequential is a variant of Scope without the functions that start
+// goroutines, such as pmap. It is intended for use by clients that
+// provide the stream module to scripts that shouldn't be able to use
+// more than one thread, such as via importer.Sandbox.
+var Sequential = wdte.S().Custom(
+	func(id wdte.ID) wdte.Func {
+		if _, ok := parallelIDs[id]; ok {
+			return nil
+		}
+		return Scope.Get(id)
+	},
+	func(known map[wdte.ID]struct{}) {
+		for _, id := range Scope.Known() {
+			if _, ok := parallelIDs[id]; !ok {
+				known[id] = struct{}{}
+			}
+		}
+	},
+)
+
 func init() {
 	std.Register("stream", Scope)
 }
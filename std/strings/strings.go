@@ -2,7 +2,9 @@
 package strings
 
 import (
+	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/DeedleFake/wdte"
 	"github.com/DeedleFake/wdte/std"
@@ -73,7 +75,8 @@ func Suffix(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 //
 // It returns the index of the first character of the first instances
 // of inner in outer. If inner is not a substring of outer, it returns
-// -1.
+// -1. Like with at, the index is in terms of Unicode code points, not
+// bytes.
 func Index(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	frame = frame.Sub("index")
 
@@ -84,7 +87,11 @@ func Index(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	haystack := args[0].(wdte.String)
 	needle := args[1].(wdte.String)
 
-	return wdte.Number(strings.Index(string(haystack), string(needle)))
+	i := strings.Index(string(haystack), string(needle))
+	if i < 0 {
+		return wdte.Number(i)
+	}
+	return wdte.Number(utf8.RuneCountInString(string(haystack[:i])))
 }
 
 // Upper is a WDTE function with the following signatures:
@@ -136,18 +143,26 @@ func Repeat(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	}
 
 	var str wdte.String
-	var times wdte.Number
+	var times wdte.Func
 	switch a0 := args[0].(type) {
 	case wdte.String:
 		str = a0
-		times = args[1].(wdte.Number)
+		times = args[1]
 
-	case wdte.Number:
+	default:
 		times = a0
 		str = args[1].(wdte.String)
 	}
 
-	return wdte.String(strings.Repeat(string(str), int(times)))
+	n, err := wdte.ToInt(times)
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+	if n < 0 {
+		return wdte.Error{Err: fmt.Errorf("negative repeat count: %v", n), Frame: frame}
+	}
+
+	return wdte.String(strings.Repeat(string(str), n))
 }
 
 // Split is a WDTE function with the following signatures:
@@ -174,15 +189,15 @@ func Split(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	str := args[0].(wdte.String)
 
 	var sep wdte.String
-	n := wdte.Number(-1)
+	var count wdte.Func = wdte.Number(-1)
 	switch arg := args[1].(type) {
 	case wdte.String:
 		sep = arg
 		if len(args) > 2 {
-			n = args[2].(wdte.Number)
+			count = args[2]
 		}
 
-	case wdte.Number:
+	default:
 		if len(args) < 3 {
 			return wdte.GoFunc(func(frame wdte.Frame, next ...wdte.Func) wdte.Func {
 				return Split(frame, append(next, args...)...)
@@ -190,13 +205,18 @@ func Split(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 		}
 
 		sep = args[2].(wdte.String)
-		n = arg
+		count = arg
+	}
+
+	n, err := wdte.ToInt(count)
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
 	}
 	if n == 0 {
 		n = -1
 	}
 
-	split := strings.SplitN(string(str), string(sep), int(n))
+	split := strings.SplitN(string(str), string(sep), n)
 
 	out := make(wdte.Array, 0, len(split))
 	for _, part := range split {
@@ -228,6 +248,46 @@ func Join(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 	return wdte.String(strings.Join(s, string(args[1].(wdte.String))))
 }
 
+// Runes is a WDTE function with the following signature:
+//
+//    runes s
+//
+// It returns an array containing the individual Unicode code points
+// of s, each as a string.
+func Runes(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("runes")
+
+	switch len(args) {
+	case 0:
+		return wdte.GoFunc(Runes)
+	}
+
+	str := args[0].(wdte.String)
+
+	r := make(wdte.Array, 0, len(str))
+	for _, c := range str {
+		r = append(r, wdte.String(c))
+	}
+	return r
+}
+
+// Bytes is a WDTE function with the following signature:
+//
+//    bytes s
+//
+// It returns the UTF-8 encoded bytes of s. This can be used with len
+// and at to work with s in terms of bytes rather than code points.
+func Bytes(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("bytes")
+
+	switch len(args) {
+	case 0:
+		return wdte.GoFunc(Bytes)
+	}
+
+	return wdte.Bytes(args[0].(wdte.String))
+}
+
 type reader struct {
 	*strings.Reader
 }
@@ -269,6 +329,9 @@ var Scope = wdte.S().Map(map[wdte.ID]wdte.Func{
 	"split":  wdte.GoFunc(Split),
 	"join":   wdte.GoFunc(Join),
 
+	"runes": wdte.GoFunc(Runes),
+	"bytes": wdte.GoFunc(Bytes),
+
 	"read":   wdte.GoFunc(Read),
 	"format": wdte.GoFunc(Format),
 })
@@ -0,0 +1,532 @@
+// Package sync provides WDTE functions for running functions
+// concurrently and communicating between them.
+//
+// Functions started with spawn run on their own goroutine with a
+// context derived from the context of the frame that spawned them.
+// Cancelling a task, or the context of the frame that spawned it,
+// causes any WDTE function calls made by the task to fail, as well as
+// any blocking operations provided by this package.
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/DeedleFake/wdte"
+	"github.com/DeedleFake/wdte/std"
+	"github.com/DeedleFake/wdte/std/stream"
+	"github.com/DeedleFake/wdte/wdteutil"
+)
+
+// A Task is a function call running on its own goroutine. When called
+// as a WDTE function, a Task simply returns itself.
+type Task struct {
+	done   chan struct{}
+	cancel context.CancelFunc
+	r      wdte.Func
+}
+
+// Go starts a new Task that calls f with the given arguments on its
+// own goroutine. The frame passed to f is given a new context derived
+// from frame's context that is cancelled when the Task is cancelled.
+func Go(frame wdte.Frame, f wdte.Func, args ...wdte.Func) *Task {
+	ctx, cancel := context.WithCancel(frame.Context())
+	t := &Task{
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+
+	go func() {
+		defer close(t.done)
+		defer cancel()
+
+		t.r = call(frame.WithContext(ctx), f, args...)
+	}()
+
+	return t
+}
+
+// call calls f, converting any panics into a wdte.Error so that they
+// don't bring down the whole program from a separate goroutine.
+func call(frame wdte.Frame, f wdte.Func, args ...wdte.Func) (r wdte.Func) {
+	defer func() {
+		if p := recover(); p != nil {
+			err, ok := p.(error)
+			if !ok {
+				err = fmt.Errorf("%v", p)
+			}
+			r = wdte.Error{Err: err, Frame: frame}
+		}
+	}()
+
+	return f.Call(frame, args...)
+}
+
+func (t *Task) Call(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	return t
+}
+
+// Done returns a channel that is closed when the Task has finished.
+func (t *Task) Done() <-chan struct{} {
+	return t.done
+}
+
+// Cancel cancels the Task's context. It does not wait for the Task to
+// finish.
+func (t *Task) Cancel() {
+	t.cancel()
+}
+
+// Wait waits for the Task to finish and returns its result. If ctx is
+// cancelled first, it returns ctx's error instead.
+func (t *Task) Wait(ctx context.Context) (wdte.Func, error) {
+	select {
+	case <-t.done:
+		return t.r, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (t *Task) String() string {
+	return "<task>"
+}
+
+func (t *Task) Reflect(name string) bool {
+	return name == "Task"
+}
+
+// A Chan is a channel that can be used to pass values between tasks.
+// When called as a WDTE function, a Chan simply returns itself. A
+// Chan is also a stream.Stream which yields values received from the
+// channel until it is closed.
+type Chan struct {
+	c       chan wdte.Func
+	closing chan struct{}
+
+	// sending is held for reading for the duration of every send so
+	// that c isn't closed while a send is in progress.
+	sending sync.RWMutex
+
+	m      sync.Mutex
+	closed bool
+}
+
+func newChan(size int) *Chan {
+	return &Chan{
+		c:       make(chan wdte.Func, size),
+		closing: make(chan struct{}),
+	}
+}
+
+func (c *Chan) Call(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	return c
+}
+
+// Next receives the next value from c. If frame's context is
+// cancelled before a value is available, a wdte.Error is yielded.
+func (c *Chan) Next(frame wdte.Frame) (wdte.Func, bool) {
+	select {
+	case v, ok := <-c.c:
+		return v, ok
+	case <-frame.Context().Done():
+		return wdte.Error{Err: frame.Context().Err(), Frame: frame}, true
+	}
+}
+
+// send sends v on c, blocking until it can be sent or ctx is
+// cancelled. Sends that are in progress when c is closed fail.
+func (c *Chan) send(ctx context.Context, v wdte.Func) error {
+	c.sending.RLock()
+	defer c.sending.RUnlock()
+
+	select {
+	case <-c.closing:
+		return errSendClosed
+	default:
+	}
+
+	select {
+	case c.c <- v:
+		return nil
+	case <-c.closing:
+		return errSendClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close closes c. It returns an error if c was already closed.
+func (c *Chan) close() error {
+	c.m.Lock()
+	if c.closed {
+		c.m.Unlock()
+		return errCloseClosed
+	}
+	c.closed = true
+	close(c.closing)
+	c.m.Unlock()
+
+	c.sending.Lock()
+	defer c.sending.Unlock()
+
+	close(c.c)
+	return nil
+}
+
+func (c *Chan) String() string {
+	return "<chan>"
+}
+
+func (c *Chan) Reflect(name string) bool {
+	return (name == "Chan") || (name == "Stream")
+}
+
+var (
+	errSendClosed  = errors.New("send on closed channel")
+	errCloseClosed = errors.New("close of closed channel")
+)
+
+// Spawn is a WDTE function with the following signature:
+//
+//    spawn f ...
+//
+// Calls f with the remaining arguments on a new goroutine, returning
+// a Task that can be used to wait for the result. Note that a lambda
+// with no arguments is evaluated where it is written, so a function
+// that should be run without arguments needs to be either a function
+// from Go or partially applied beforehand.
+func Spawn(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("spawn")
+
+	if len(args) == 0 {
+		return wdte.GoFunc(Spawn)
+	}
+
+	return Go(frame, args[0], args[1:]...)
+}
+
+// Await is a WDTE function with the following signature:
+//
+//    await t
+//
+// Waits for the task t to finish and returns its result. If the
+// current context is cancelled first, an error is returned.
+func Await(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("await")
+
+	if len(args) == 0 {
+		return wdte.GoFunc(Await)
+	}
+
+	r, err := args[0].(*Task).Wait(frame.Context())
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+	return r
+}
+
+// Cancel is a WDTE function with the following signature:
+//
+//    cancel t
+//
+// Cancels the task t and returns it without waiting for it to finish.
+func Cancel(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("cancel")
+
+	if len(args) == 0 {
+		return wdte.GoFunc(Cancel)
+	}
+
+	t := args[0].(*Task)
+	t.Cancel()
+	return t
+}
+
+// waitAll waits for all of the tasks in tasks to finish, calling
+// handle with the index of each task as it does. If handle returns
+// false, waitAll returns immediately.
+func waitAll(ctx context.Context, tasks []*Task, handle func(int) bool) error {
+	cases := make([]reflect.SelectCase, 0, len(tasks)+1)
+	cases = append(cases, reflect.SelectCase{
+		Dir:  reflect.SelectRecv,
+		Chan: reflect.ValueOf(ctx.Done()),
+	})
+	for _, t := range tasks {
+		cases = append(cases, reflect.SelectCase{
+			Dir:  reflect.SelectRecv,
+			Chan: reflect.ValueOf(t.done),
+		})
+	}
+
+	for range tasks {
+		i, _, _ := reflect.Select(cases)
+		if i == 0 {
+			return ctx.Err()
+		}
+
+		// A nil channel blocks forever, removing the case.
+		cases[i].Chan = reflect.ValueOf((chan struct{})(nil))
+		if !handle(i - 1) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func toTasks(a wdte.Array) []*Task {
+	tasks := make([]*Task, 0, len(a))
+	for _, t := range a {
+		tasks = append(tasks, t.(*Task))
+	}
+	return tasks
+}
+
+// WaitAll is a WDTE function with the following signature:
+//
+//    waitAll tasks
+//
+// Waits for all of the tasks in the array tasks to finish, returning
+// an array of their results in the same order as the tasks. If any of
+// the tasks returns an error, the remaining tasks are cancelled and
+// the error is returned.
+func WaitAll(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("waitAll")
+
+	if len(args) == 0 {
+		return wdte.GoFunc(WaitAll)
+	}
+
+	tasks := toTasks(args[0].(wdte.Array))
+
+	r := make(wdte.Array, len(tasks))
+	var failed wdte.Func
+	err := waitAll(frame.Context(), tasks, func(i int) bool {
+		if _, ok := tasks[i].r.(error); ok {
+			failed = tasks[i].r
+			return false
+		}
+
+		r[i] = tasks[i].r
+		return true
+	})
+	if (err != nil) || (failed != nil) {
+		for _, t := range tasks {
+			t.Cancel()
+		}
+	}
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+	if failed != nil {
+		return failed
+	}
+
+	return r
+}
+
+// WaitAny is a WDTE function with the following signature:
+//
+//    waitAny tasks
+//
+// Waits for any one of the tasks in the array tasks to finish and
+// returns its result. The other tasks are left running.
+func WaitAny(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("waitAny")
+
+	if len(args) == 0 {
+		return wdte.GoFunc(WaitAny)
+	}
+
+	tasks := toTasks(args[0].(wdte.Array))
+	if len(tasks) == 0 {
+		return wdte.Error{Err: fmt.Errorf("no tasks to wait for"), Frame: frame}
+	}
+
+	var r wdte.Func
+	err := waitAll(frame.Context(), tasks, func(i int) bool {
+		r = tasks[i].r
+		return false
+	})
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+
+	return r
+}
+
+// NewChan is a WDTE function with the following signature:
+//
+//    chan n
+//
+// Returns a new channel with a buffer size of n.
+func NewChan(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("chan")
+
+	if len(args) == 0 {
+		return wdte.GoFunc(NewChan)
+	}
+
+	n, err := wdte.ToInt(args[0])
+	if err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+	if n < 0 {
+		return wdte.Error{Err: fmt.Errorf("negative buffer size: %v", n), Frame: frame}
+	}
+
+	return newChan(n)
+}
+
+// Send is a WDTE function with the following signatures:
+//
+//    send c v
+//    (send v) c
+//
+// Sends v on the channel c, blocking until there is room in its
+// buffer or another task receives the value. Returns c. Sending on a
+// channel that is closed, including one that is closed while the send
+// is waiting, is an error.
+func Send(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("send")
+
+	if len(args) < 2 {
+		return wdteutil.SaveArgsReverse(wdte.GoFunc(Send), args...)
+	}
+
+	c := args[0].(*Chan)
+	if err := c.send(frame.Context(), args[1]); err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+	return c
+}
+
+// Recv is a WDTE function with the following signature:
+//
+//    recv c
+//
+// Receives a value from the channel c, blocking until one is
+// available. If c is closed, the end-of-stream marker, stream.end, is
+// returned.
+func Recv(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("recv")
+
+	if len(args) == 0 {
+		return wdte.GoFunc(Recv)
+	}
+
+	v, ok := args[0].(*Chan).Next(frame)
+	if !ok {
+		return stream.End()
+	}
+	return v
+}
+
+// Close is a WDTE function with the following signature:
+//
+//    close c
+//
+// Closes the channel c and returns it. Any sends on c that are
+// waiting when it is closed fail. Closing a channel that is already
+// closed is an error.
+func Close(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("close")
+
+	if len(args) == 0 {
+		return wdte.GoFunc(Close)
+	}
+
+	c := args[0].(*Chan)
+	if err := c.close(); err != nil {
+		return wdte.Error{Err: err, Frame: frame}
+	}
+	return c
+}
+
+// Select is a WDTE function with the following signature:
+//
+//    select cases
+//
+// Waits for one of a number of operations to be ready. cases is an
+// array of two-element arrays, each consisting of either a channel or
+// a task followed by a function. When a value is received from one of
+// the channels, the corresponding function is called with it, or with
+// stream.end if the channel was closed. When one of the tasks
+// finishes, the corresponding function is called with its result. If
+// more than one case is ready, one is chosen at random. The result of
+// the function that was called is returned.
+func Select(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	frame = frame.Sub("select")
+
+	if len(args) == 0 {
+		return wdte.GoFunc(Select)
+	}
+
+	a := args[0].(wdte.Array)
+	cases := make([]reflect.SelectCase, 0, len(a)+1)
+	cases = append(cases, reflect.SelectCase{
+		Dir:  reflect.SelectRecv,
+		Chan: reflect.ValueOf(frame.Context().Done()),
+	})
+	for _, c := range a {
+		c := c.(wdte.Array)
+
+		var ch reflect.Value
+		switch src := c[0].(type) {
+		case *Chan:
+			ch = reflect.ValueOf(src.c)
+		case *Task:
+			ch = reflect.ValueOf(src.done)
+		default:
+			return wdte.Error{
+				Err:   fmt.Errorf("cannot select on %v", src),
+				Frame: frame,
+			}
+		}
+
+		cases = append(cases, reflect.SelectCase{
+			Dir:  reflect.SelectRecv,
+			Chan: ch,
+		})
+	}
+
+	i, v, ok := reflect.Select(cases)
+	if i == 0 {
+		return wdte.Error{Err: frame.Context().Err(), Frame: frame}
+	}
+
+	c := a[i-1].(wdte.Array)
+	switch src := c[0].(type) {
+	case *Chan:
+		if !ok {
+			return c[1].Call(frame, stream.End())
+		}
+		return c[1].Call(frame, v.Interface().(wdte.Func))
+
+	default:
+		return c[1].Call(frame, src.(*Task).r)
+	}
+}
+
+// Scope is a scope containing the functions in this package.
+var Scope = wdte.S().Map(map[wdte.ID]wdte.Func{
+	"spawn":   wdte.GoFunc(Spawn),
+	"await":   wdte.GoFunc(Await),
+	"cancel":  wdte.GoFunc(Cancel),
+	"waitAll": wdte.GoFunc(WaitAll),
+	"waitAny": wdte.GoFunc(WaitAny),
+
+	"chan":   wdte.GoFunc(NewChan),
+	"send":   wdte.GoFunc(Send),
+	"recv":   wdte.GoFunc(Recv),
+	"close":  wdte.GoFunc(Close),
+	"select": wdte.GoFunc(Select),
+})
+
+func init() {
+	std.Register("sync", Scope)
+}
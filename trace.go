@@ -0,0 +1,195 @@
+package wdte
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// A Tracer observes the evaluation of WDTE code, allowing clients to
+// build tools such as profilers, coverage tools, and debuggers. A
+// tracer is attached to a frame with WithTracer, and is inherited by
+// all frames derived from that frame.
+//
+// The methods of a Tracer are called synchronously during
+// evaluation, so they should return quickly. If the code being
+// evaluated uses multiple goroutines, such as via the sync module,
+// they may be called concurrently. Each call that the tracer is told
+// about can be identified by the *TracedCall of the frame passed to
+// Enter and Exit, which remains correct when that happens.
+type Tracer interface {
+	// Enter is called when a function is about to be called with the
+	// given arguments. It is called for Go functions and for lambdas,
+	// including functions declared with let, but not for other kinds
+	// of values that are called, such as numbers. It is also not
+	// called for Go functions called with no arguments, which, by
+	// convention, return themselves, as that happens every time that
+	// one is referenced. For a lambda, frame is the frame that its body
+	// is evaluated in, the ID of which is the lambda's ID. For a Go
+	// function, frame is the frame that it was called with. In both
+	// cases, frame.TracedCall describes the call.
+	Enter(frame Frame, f Func, args []Func)
+
+	// Exit is called when a function that Enter was called for
+	// returns, with the same frame, function, and arguments, as well
+	// as the function's return value. It is also called if the
+	// function panics, so every call to Enter has a matching call to
+	// Exit. A GoFunc that panics with an error returns it as an Error,
+	// as described in GoFunc's documentation, and r is that Error. If
+	// a panic isn't recovered, Exit is called while the panic unwinds
+	// the stack and r is nil.
+	Exit(frame Frame, f Func, args []Func, r Func)
+
+	// Lookup is called when a variable is looked up in the scope of
+	// frame. f is the value that was found, or nil if the variable was
+	// not in scope.
+	Lookup(frame Frame, id ID, f Func)
+
+	// Error is called when a function call results in an error that
+	// wasn't simply passed to it as an argument, when a variable isn't
+	// in scope, or when a Go function panics. Because errors are
+	// returned like other values, the same error may be reported more
+	// than once if it is returned through several levels of function
+	// calls.
+	Error(frame Frame, err error)
+}
+
+// NopTracer is a Tracer that does nothing. It can be embedded into
+// other types in order to implement Tracer without having to
+// implement every method.
+type NopTracer struct{}
+
+func (NopTracer) Enter(frame Frame, f Func, args []Func)        {}
+func (NopTracer) Exit(frame Frame, f Func, args []Func, r Func) {}
+func (NopTracer) Lookup(frame Frame, id ID, f Func)             {}
+func (NopTracer) Error(frame Frame, err error)                  {}
+
+// WithTracer returns a copy of f with the given tracer attached to
+// it. If t is nil, the returned frame has no tracer.
+func (f Frame) WithTracer(t Tracer) Frame {
+	f.tracer = t
+	return f
+}
+
+// Tracer returns the tracer attached to the frame, or nil if there is
+// none.
+func (f Frame) Tracer() Tracer {
+	return f.tracer
+}
+
+// A TracedCall is a call that a tracer was told about. It is returned
+// by the TracedCall method of the frame passed to the tracer's Enter
+// and Exit methods, and the same *TracedCall is used for both, so it
+// can be used to match calls with their returns.
+type TracedCall struct {
+	// Parent is the traced call that this one was made during, or nil
+	// if there isn't one.
+	Parent *TracedCall
+
+	// Depth is the number of calls in the chain of parents.
+	Depth int
+
+	// Name is the name of the function being called. For a lambda,
+	// this is its ID. For a Go function, it is the variable that it
+	// was called through, such as + or s.map, if known, and the name
+	// of the underlying Go function otherwise.
+	Name ID
+
+	// Pos is the position in the script's source that the call was
+	// made from, if known. For a function called by a Go function,
+	// such as one passed to s.map, it is the position that the Go
+	// function was called from.
+	Pos Pos
+}
+
+// TracedCall returns the innermost traced call that the frame is
+// being used in, or nil if there isn't one, such as if the frame has
+// no tracer.
+func (f Frame) TracedCall() *TracedCall {
+	return f.call
+}
+
+// callSite is the location of a call that is being made in a frame
+// with a tracer.
+type callSite struct {
+	pos Pos
+
+	// expr is the expression that fn was the result of, such as a Var.
+	expr Func
+	fn   Func
+}
+
+// at returns a copy of f for calling fn, the result of expr, from
+// pos.
+func (f Frame) at(pos Pos, expr, fn Func) Frame {
+	f.site = &callSite{pos: pos, expr: expr, fn: fn}
+	return f
+}
+
+// traced returns a copy of f for a new traced call to a function
+// with the given name.
+func (f Frame) traced(name ID) Frame {
+	c := &TracedCall{
+		Parent: f.call,
+		Name:   name,
+	}
+	if c.Parent != nil {
+		c.Depth = c.Parent.Depth + 1
+	}
+	if f.site != nil {
+		c.Pos = f.site.pos
+	}
+
+	// Calls made during this one that don't have sites of their own,
+	// such as those made by a Go function, are made from the same
+	// position, but they weren't the result of the same expression.
+	f.site = &callSite{pos: c.Pos}
+	f.call = c
+	return f
+}
+
+// name returns the name of f when it is called from site. See
+// TracedCall.Name.
+func (f GoFunc) name(site *callSite) ID {
+	pc := reflect.ValueOf(f).Pointer()
+	if site != nil {
+		fn, ok := site.fn.(GoFunc)
+		if ok && (reflect.ValueOf(fn).Pointer() == pc) {
+			if name := exprName(site.expr); name != "" {
+				return name
+			}
+		}
+	}
+
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		name := fn.Name()
+		return ID(name[strings.LastIndexByte(name, '/')+1:])
+	}
+	return "<unknown>"
+}
+
+// exprName returns the name of the variable that expr, or the
+// function called by expr, refers to, or an empty string if it
+// doesn't refer to one.
+func exprName(expr Func) ID {
+	switch expr := expr.(type) {
+	case Var:
+		return ID(expr)
+
+	case Sub:
+		parts := make([]string, 0, len(expr))
+		for _, e := range expr {
+			v, ok := e.(Var)
+			if !ok {
+				return ""
+			}
+			parts = append(parts, string(v))
+		}
+		return ID(strings.Join(parts, "."))
+
+	case *FuncCall:
+		return exprName(expr.Func)
+	}
+
+	return ""
+}
@@ -2,6 +2,7 @@ package wdte
 
 import (
 	"fmt"
+	"math/big"
 	"runtime"
 
 	"github.com/DeedleFake/wdte/ast"
@@ -10,6 +11,25 @@ import (
 
 type translator struct {
 	im Importer
+
+	// runtimeImports causes imports of string literals to be done at
+	// runtime, like those of variables, rather than using im.
+	runtimeImports bool
+}
+
+// pos returns the position of the first token in n.
+func pos(n ast.Node) Pos {
+	if t, ok := n.(*ast.Term); ok {
+		tok := t.Tok()
+		return Pos{Line: tok.Line, Col: tok.Col}
+	}
+
+	for _, c := range n.Children() {
+		if p := pos(c); p != (Pos{}) {
+			return p
+		}
+	}
+	return Pos{}
 }
 
 func (m *translator) fromScript(script *ast.NTerm) (c Compound, err error) {
@@ -87,6 +107,7 @@ func (m *translator) fromExpr(expr *ast.NTerm, flags uint, chain Chain) (r Func)
 	r = &FuncCall{
 		Func: first,
 		Args: in,
+		Pos:  pos(expr),
 	}
 	r = m.fromSwitch(expr.Children()[2].(*ast.NTerm), r)
 
@@ -95,6 +116,7 @@ func (m *translator) fromExpr(expr *ast.NTerm, flags uint, chain Chain) (r Func)
 
 		Flags: flags,
 		Slots: slots,
+		Pos:   pos(expr),
 	}
 
 	fc := m.fromChain(expr.Children()[4].(*ast.NTerm), append(chain, piece))
@@ -116,13 +138,15 @@ func (m *translator) fromLetExpr(expr *ast.NTerm) Func {
 
 		return &LetAssigner{
 			Assigner: SimpleAssigner(id),
-			Expr:     m.fromFuncDecl(mods, id, args, inner),
+			Expr:     m.fromFuncDecl(mods, id, args, inner, pos(expr)),
+			Pos:      pos(expr),
 		}
 
 	case "argdecl":
 		return &LetAssigner{
 			Assigner: m.fromArgDecl(first),
 			Expr:     m.fromExpr(assign.Children()[2].(*ast.NTerm), 0, nil),
+			Pos:      pos(expr),
 		}
 	}
 
@@ -143,7 +167,16 @@ func (m *translator) fromSingle(single *ast.NTerm) Func {
 	case *ast.Term:
 		switch s.Tok().Type {
 		case scanner.Number:
-			return Number(s.Tok().Val.(float64))
+			switch v := s.Tok().Val.(type) {
+			case float64:
+				return Number(v)
+			case int64:
+				return Int(v)
+			case *big.Int:
+				return BigInt{v}
+			case *big.Rat:
+				return BigRat{v}
+			}
 		case scanner.String:
 			return String(s.Tok().Val.(string))
 		}
@@ -260,7 +293,7 @@ func (m *translator) fromCompound(compound *ast.NTerm) Func {
 	return r
 }
 
-func (m *translator) fromFuncDecl(mods Func, id ID, args []Assigner, expr Func) Func {
+func (m *translator) fromFuncDecl(mods Func, id ID, args []Assigner, expr Func, p Pos) Func {
 	if len(args) == 0 {
 		if mods == nil {
 			return expr
@@ -276,6 +309,7 @@ func (m *translator) fromFuncDecl(mods Func, id ID, args []Assigner, expr Func)
 		ID:   id,
 		Expr: expr,
 		Args: args,
+		Pos:  p,
 	}
 
 	if mods == nil {
@@ -301,16 +335,31 @@ func (m *translator) fromLambda(lambda *ast.NTerm) (f Func) {
 		}
 	}
 
-	return m.fromFuncDecl(mods, id, args, inner)
+	return m.fromFuncDecl(mods, id, args, inner, pos(lambda))
 }
 
 func (m *translator) fromImport(im *ast.NTerm) Func {
-	s, err := m.im.Import(im.Children()[1].(*ast.Term).Tok().Val.(string))
-	if err != nil {
-		panic(err)
+	switch src := im.Children()[1].(*ast.NTerm).Children()[0].(type) {
+	case *ast.Term:
+		if m.runtimeImports {
+			return Import{Expr: String(src.Tok().Val.(string))}
+		}
+
+		s, err := m.im.Import(src.Tok().Val.(string))
+		if err != nil {
+			panic(err)
+		}
+		return s
+
+	case *ast.NTerm:
+		sub := m.fromSubbable(src, nil)
+		if len(sub) == 1 {
+			return Import{Expr: sub[0]}
+		}
+		return Import{Expr: sub}
 	}
 
-	return s
+	panic(fmt.Errorf("Malformed AST with bad <importsrc>: %#v", im))
 }
 
 func (m *translator) fromExprs(exprs *ast.NTerm, funcs []Func) []Func {
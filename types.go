@@ -1,10 +1,14 @@
 package wdte
 
 import (
+	"bytes"
 	"fmt"
+	"hash/fnv"
+	"math"
 	"math/big"
 	"reflect"
 	"strings"
+	"unicode/utf8"
 )
 
 // A Comparer is a Func that is able to be compared to other
@@ -19,6 +23,97 @@ type Comparer interface {
 	Compare(other Func) (int, bool)
 }
 
+// Equal checks if two Funcs are equal. If a implements Comparer, its
+// implementation is used. If a does not but b does, b's
+// implementation is used. If neither does, a direct Go equality check
+// is done, with values of types that Go can't compare always being
+// considered unequal.
+func Equal(a, b Func) bool {
+	if cmp, ok := a.(Comparer); ok {
+		c, _ := cmp.Compare(b)
+		return c == 0
+	}
+
+	if cmp, ok := b.(Comparer); ok {
+		c, _ := cmp.Compare(a)
+		return c == 0
+	}
+
+	if (a == nil) || (b == nil) {
+		return a == b
+	}
+
+	t := reflect.TypeOf(a)
+	if (t != reflect.TypeOf(b)) || !t.Comparable() {
+		return false
+	}
+
+	return a == b
+}
+
+// compare compares a and b, returning results in the same form as
+// Comparer's Compare method. If neither a nor b implement Comparer,
+// the result is determined by Equal and is unordered.
+func compare(a, b Func) (int, bool) {
+	if cmp, ok := a.(Comparer); ok {
+		return cmp.Compare(b)
+	}
+
+	if cmp, ok := b.(Comparer); ok {
+		c, ord := cmp.Compare(a)
+		return -c, ord
+	}
+
+	if Equal(a, b) {
+		return 0, false
+	}
+	return -1, false
+}
+
+// A Hasher is a Func that can produce a hash of its value, allowing it
+// to be used as a key in hash-based lookups, such as the cache used
+// by the memo function modifier. Any two Funcs that are equal
+// according to Equal must produce the same hash.
+type Hasher interface {
+	Hash() uint64
+}
+
+// Hash returns a hash of f. If f implements Hasher, its
+// implementation is used. If not, 0 is returned, which is still
+// valid, if not particularly useful, as it simply results in every
+// such Func colliding with every other one.
+func Hash(f Func) uint64 {
+	if h, ok := f.(Hasher); ok {
+		return h.Hash()
+	}
+
+	return 0
+}
+
+const (
+	hashOffset = 14695981039346656037
+	hashPrime  = 1099511628211
+)
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+func hashUint(v uint64) uint64 {
+	v ^= v >> 30
+	v *= 0xbf58476d1ce4e5b9
+	v ^= v >> 27
+	v *= 0x94d049bb133111eb
+	v ^= v >> 31
+	return v
+}
+
+func hashCombine(h, v uint64) uint64 {
+	return (h ^ v) * hashPrime
+}
+
 // A Lenner is a Func that has a length, such as arrays and strings.
 type Lenner interface {
 	Len() int
@@ -29,6 +124,13 @@ type Atter interface {
 	At(i Func) (Func, error)
 }
 
+// A Scoper is a Func that isn't a scope itself, but which can provide
+// one for use with the sub syntax. For example, if x is a Scoper, the
+// expression x.y evaluates y in the scope returned by x.Scope().
+type Scoper interface {
+	Scope() *Scope
+}
+
 // A Setter is a Func that can produce a new Func from itself with a
 // key-value mapping applied in some way. For example, a scope can
 // produce a subscope with a new variable added to it, or an array can
@@ -87,23 +189,115 @@ func (s String) Compare(other Func) (int, bool) {
 	return 0, true
 }
 
+func (s String) Hash() uint64 {
+	return hashString(string(s))
+}
+
+// Len returns the number of Unicode code points in s. For the number
+// of bytes, convert s to Bytes first.
 func (s String) Len() int {
-	return len(s)
+	return utf8.RuneCountInString(string(s))
 }
 
+// At returns the Unicode code point at the given index as a String.
+// Indices are in terms of code points, not bytes.
 func (s String) At(index Func) (Func, error) {
-	i := int(index.(Number))
-	if (i < 0) || (i >= len(s)) {
-		return nil, fmt.Errorf("index %v is out of range [0,%v)", i, len(s))
+	i, err := toIndex(index)
+	if err != nil {
+		return nil, err
+	}
+
+	if i >= 0 {
+		var n int
+		for _, c := range s {
+			if n == i {
+				return String(c), nil
+			}
+			n++
+		}
 	}
 
-	return String(s[i]), nil
+	return nil, IndexError{Index: i, Len: s.Len()}
 }
 
 func (s String) Reflect(name string) bool {
 	return name == "String"
 }
 
+// Bytes is a sequence of raw bytes. Unlike String, it makes no
+// assumptions about the encoding of its contents, making it suitable
+// for binary data. Like other primitive types, it simply returns
+// itself when called. A Bytes should be treated as immutable once it
+// has been created.
+type Bytes []byte
+
+func (b Bytes) Call(frame Frame, args ...Func) Func {
+	return b
+}
+
+func (b Bytes) Compare(other Func) (int, bool) {
+	o, ok := other.(Bytes)
+	if !ok {
+		return -1, false
+	}
+
+	return bytes.Compare(b, o), true
+}
+
+func (b Bytes) Hash() uint64 {
+	return hashString(string(b))
+}
+
+func (b Bytes) Len() int {
+	return len(b)
+}
+
+// At returns the byte at the given index as a Number.
+func (b Bytes) At(index Func) (Func, error) {
+	i, err := toIndex(index)
+	if err != nil {
+		return nil, err
+	}
+	if (i < 0) || (i >= len(b)) {
+		return nil, IndexError{Index: i, Len: len(b)}
+	}
+
+	return Number(b[i]), nil
+}
+
+// Set returns a copy of b with the byte at index k set to v, which
+// must be a number in the range [0,255].
+func (b Bytes) Set(k, v Func) (Func, error) {
+	i, err := toIndex(k)
+	if err != nil {
+		return nil, err
+	}
+	if (i < 0) || (i >= len(b)) {
+		return nil, IndexError{Index: i, Len: len(b)}
+	}
+
+	c, err := ToInt(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid byte value: %w", err)
+	}
+	if (c < 0) || (c > 255) {
+		return nil, fmt.Errorf("byte value %v is out of range [0,255]", v)
+	}
+
+	n := make(Bytes, len(b))
+	copy(n, b)
+	n[i] = byte(c)
+	return n, nil
+}
+
+func (b Bytes) String() string {
+	return fmt.Sprintf("<bytes %x>", []byte(b))
+}
+
+func (b Bytes) Reflect(name string) bool {
+	return name == "Bytes"
+}
+
 // A Number is a number, as parsed from a number literal. That's about
 // it. Like everything else, it's a function. It simply returns itself
 // when called.
@@ -115,19 +309,18 @@ func (n Number) Call(frame Frame, args ...Func) Func {
 }
 
 func (n Number) Compare(other Func) (int, bool) {
-	o, ok := other.(Number)
-
-	switch {
-	case !ok:
-		return -1, false
+	return compareNumeric(n, other)
+}
 
-	case n < o:
-		return -1, true
-	case n > o:
-		return 1, true
-	default:
-		return 0, true
+// Hash returns a hash of n. Integral values are hashed as integers so
+// that they hash the same way regardless of their representation.
+func (n Number) Hash() uint64 {
+	f := float64(n)
+	if (f == math.Trunc(f)) && (math.Abs(f) < 1<<63) {
+		return hashUint(uint64(int64(f)))
 	}
+
+	return hashUint(math.Float64bits(f))
 }
 
 func (n Number) String() string {
@@ -162,18 +355,24 @@ func (a Array) Len() int {
 }
 
 func (a Array) At(index Func) (Func, error) {
-	i := int(index.(Number))
+	i, err := toIndex(index)
+	if err != nil {
+		return nil, err
+	}
 	if (i < 0) || (i >= len(a)) {
-		return nil, fmt.Errorf("index %v is out of range [0,%v)", i, len(a))
+		return nil, IndexError{Index: i, Len: len(a)}
 	}
 
 	return a[i], nil
 }
 
 func (a Array) Set(k, v Func) (Func, error) {
-	i := int(k.(Number))
+	i, err := toIndex(k)
+	if err != nil {
+		return nil, err
+	}
 	if (i < 0) || (i >= len(a)) {
-		return nil, fmt.Errorf("index %v is out of bounds [0,%v]", i, len(a))
+		return nil, IndexError{Index: i, Len: len(a)}
 	}
 
 	c := make(Array, len(a))
@@ -201,11 +400,41 @@ func (a Array) Reflect(name string) bool {
 	return name == "Array"
 }
 
-//func (a Array)Compare(other Func) (int, bool) {
-//	TODO: Implement this. I'm not sure if it should support ordering
-//	or not. I'm also not sure if it should call its elements in order
-//	to get their underlying values. It probably should.
-//}
+func (a Array) Hash() uint64 {
+	h := uint64(hashOffset)
+	for _, e := range a {
+		h = hashCombine(h, Hash(e))
+	}
+	return h
+}
+
+// Compare compares two arrays element-wise. Arrays are ordered
+// lexicographically, with the first unequal pair of elements
+// determining the result. If one array is a prefix of the other, the
+// shorter array is considered to be less than the longer one. The
+// result is only ordered if the elements that determined it are.
+func (a Array) Compare(other Func) (int, bool) {
+	o, ok := other.(Array)
+	if !ok {
+		return -1, false
+	}
+
+	for i := 0; (i < len(a)) && (i < len(o)); i++ {
+		c, ord := compare(a[i], o[i])
+		if c != 0 {
+			return c, ord
+		}
+	}
+
+	switch {
+	case len(a) < len(o):
+		return -1, true
+	case len(a) > len(o):
+		return 1, true
+	default:
+		return 0, true
+	}
+}
 
 // An Error is returned by any of the built-in functions when they run
 // into an error.
@@ -235,6 +464,18 @@ func (e Error) Reflect(name string) bool {
 	return name == "Error"
 }
 
+// An IndexError is the error returned by the At and Set methods of
+// the built-in indexable types when given an index that is out of
+// range.
+type IndexError struct {
+	Index int
+	Len   int
+}
+
+func (err IndexError) Error() string {
+	return fmt.Sprintf("index %v is out of range [0,%v)", err.Index, err.Len)
+}
+
 // Bool is a boolean. Like other primitive types, it simply returns
 // itself when called.
 type Bool bool
@@ -244,12 +485,20 @@ func (b Bool) Call(frame Frame, args ...Func) Func {
 }
 
 func (b Bool) Compare(other Func) (int, bool) {
-	if b == other {
+	o, ok := other.(Bool)
+	if ok && (b == o) {
 		return 0, false
 	}
 	return -1, false
 }
 
+func (b Bool) Hash() uint64 {
+	if b {
+		return hashUint(1)
+	}
+	return hashUint(0)
+}
+
 func (b Bool) Reflect(name string) bool {
 	return name == "Bool"
 }
@@ -7,6 +7,7 @@ import (
 	"io"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/DeedleFake/wdte/ast"
 	"github.com/DeedleFake/wdte/scanner"
@@ -63,9 +64,81 @@ func (f ImportFunc) Import(from string) (*Scope, error) {
 	return f(from)
 }
 
+// Run calls f with args using a new frame from F with ctx attached to
+// it, allowing the evaluation to be stopped by cancelling ctx or by its
+// deadline passing. If ctx is done by the time that f returns, an
+// Error wrapping ctx.Err() is returned instead of f's result, as that
+// result may be incomplete.
+//
+// For example, to run a script with a time limit:
+//
+//    ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+//    defer cancel()
+//
+//    r := wdte.Run(ctx, m)
+//
+// To run f with a custom frame, such as one with a scope containing
+// the standard library's global functions, use RunFrame.
+func Run(ctx context.Context, f Func, args ...Func) Func {
+	return RunFrame(ctx, F(), f, args...)
+}
+
+// RunFrame is like Run, but it uses frame, with ctx attached to it,
+// instead of a new frame.
+func RunFrame(ctx context.Context, frame Frame, f Func, args ...Func) Func {
+	frame = frame.WithContext(ctx)
+
+	r := f.Call(frame, args...)
+	if err, ok := frame.Cancelled(); ok {
+		return err
+	}
+	return r
+}
+
 // ID represents a WDTE ID, such as a local variable.
 type ID string
 
+// A Pos is a position in the source of a script, as given by the
+// line and column of the first token there. Lines are numbered from
+// 1. The zero Pos means that the position isn't known, such as for
+// code that wasn't parsed from a script.
+type Pos struct {
+	Line, Col int
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%v:%v", p.Line, p.Col)
+}
+
+// PosOf returns the position of f in the source of the script that it
+// was parsed from, if f is an expression from a parsed script, such as
+// an element of a Compound. Otherwise, it returns the zero Pos.
+func PosOf(f Func) Pos {
+	switch f := f.(type) {
+	case *FuncCall:
+		return f.Pos
+
+	case Chain:
+		if len(f) > 0 {
+			return f[0].Pos
+		}
+
+	case *Switch:
+		return PosOf(f.Check)
+
+	case *LetAssigner:
+		return f.Pos
+
+	case *Lambda:
+		return f.Pos
+
+	case *Modifier:
+		return PosOf(f.Func)
+	}
+
+	return Pos{}
+}
+
 // Func is the base type through which all data is handled by WDTE. It
 // represents everything that can be passed around in the language.
 // This includes functions, of course, expressions, strings, numbers,
@@ -81,9 +154,15 @@ type Func interface {
 // the scope that the function is being executed in and debugging
 // info.
 type Frame struct {
-	id    ID
-	scope *Scope
-	ctx   context.Context
+	id     ID
+	scope  *Scope
+	ctx    context.Context
+	im     *frameImporter
+	tracer Tracer
+
+	// site and call are only set while tracing. See trace.go.
+	site *callSite
+	call *TracedCall
 
 	p *Frame
 }
@@ -99,7 +178,7 @@ func F() Frame {
 }
 
 // Sub returns a new child frame of f with the given ID and the same
-// scope as f.
+// scope, context, importer, and tracer as f.
 //
 // Under most circumstances, a GoFunc should call this before calling
 // any WDTE functions, as it is useful for debugging. For example:
@@ -109,11 +188,10 @@ func F() Frame {
 //        ...
 //    }
 func (f Frame) Sub(id ID) Frame {
-	return Frame{
-		id:    id,
-		scope: f.scope,
-		p:     &f,
-	}
+	sub := f
+	sub.id = id
+	sub.p = &f
+	return sub
 }
 
 // WithScope returns a copy of f with the given scope.
@@ -128,6 +206,64 @@ func (f Frame) WithContext(ctx context.Context) Frame {
 	return f
 }
 
+// WithImporter returns a copy of f with the given importer, which is
+// used by import expressions that are evaluated at runtime, such as
+//
+//    let m => import name;
+//
+// Modules imported this way are cached, so each module is only
+// imported from im once per call to WithImporter. If im is nil, the
+// returned frame has no importer, and runtime imports will fail.
+func (f Frame) WithImporter(im Importer) Frame {
+	f.im = nil
+	if im != nil {
+		f.im = &frameImporter{im: im}
+	}
+	return f
+}
+
+// Importer returns the importer associated with the frame, or nil if
+// there is none.
+func (f Frame) Importer() Importer {
+	if f.im == nil {
+		return nil
+	}
+
+	return f.im
+}
+
+// frameImporter is a caching wrapper around the importer attached to
+// a frame.
+type frameImporter struct {
+	im Importer
+
+	m       sync.Mutex
+	modules map[string]*Scope
+}
+
+func (im *frameImporter) Import(from string) (*Scope, error) {
+	im.m.Lock()
+	s, ok := im.modules[from]
+	im.m.Unlock()
+	if ok {
+		return s, nil
+	}
+
+	s, err := im.im.Import(from)
+	if err != nil {
+		return nil, err
+	}
+
+	im.m.Lock()
+	defer im.m.Unlock()
+
+	if im.modules == nil {
+		im.modules = make(map[string]*Scope)
+	}
+	im.modules[from] = s
+	return s, nil
+}
+
 // ID returns the ID of the frame. This is generally the function that
 // created the frame.
 func (f Frame) ID() ID {
@@ -139,6 +275,8 @@ func (f Frame) Scope() *Scope {
 	return f.scope
 }
 
+// Context returns the context associated with the frame. If the
+// frame has no context, context.Background() is returned.
 func (f Frame) Context() context.Context {
 	if f.ctx == nil {
 		return context.Background()
@@ -147,6 +285,20 @@ func (f Frame) Context() context.Context {
 	return f.ctx
 }
 
+// Cancelled checks if the frame's context has been cancelled or has
+// passed its deadline. If it has, it returns an Error wrapping the
+// context's error and true. Go functions that loop or block for
+// potentially long periods of time should check this regularly so
+// that cancellation can stop them.
+func (f Frame) Cancelled() (Error, bool) {
+	err := f.Context().Err()
+	if err == nil {
+		return Error{}, false
+	}
+
+	return Error{Err: err, Frame: f}, true
+}
+
 // Parent returns the frame that this frame was created from, or a
 // blank frame if there was none.
 func (f Frame) Parent() Frame {
@@ -192,6 +344,7 @@ type Scope struct {
 	p       *Scope
 	known   func(m map[ID]struct{})
 	getFunc func(id ID) Func
+	mod     *ModuleInfo
 }
 
 // S is a convenience function that returns a blank, top-level scope.
@@ -371,6 +524,46 @@ func (s *Scope) String() string {
 	return buf.String()
 }
 
+// Compare checks two scopes for structural equality. Two scopes are
+// considered equal if they have the same known variables and the
+// values of those variables are equal as determined by Equal. Scopes
+// are not ordered.
+func (s *Scope) Compare(other Func) (int, bool) {
+	o, ok := other.(*Scope)
+	if !ok {
+		return -1, false
+	}
+	if s == o {
+		return 0, false
+	}
+
+	known, oknown := s.Known(), o.Known()
+	if len(known) != len(oknown) {
+		return -1, false
+	}
+
+	for i, id := range known {
+		if id != oknown[i] {
+			return -1, false
+		}
+
+		if !Equal(s.Get(id), o.Get(id)) {
+			return -1, false
+		}
+	}
+
+	return 0, false
+}
+
+func (s *Scope) Hash() uint64 {
+	h := uint64(hashOffset)
+	for _, id := range s.Known() {
+		h = hashCombine(h, hashString(string(id)))
+		h = hashCombine(h, Hash(s.Get(id)))
+	}
+	return h
+}
+
 func (s *Scope) Reflect(name string) bool {
 	return name == "Scope"
 }
@@ -407,14 +600,26 @@ func (s *Scope) Reflect(name string) bool {
 type GoFunc func(frame Frame, args ...Func) Func
 
 func (f GoFunc) Call(frame Frame, args ...Func) (r Func) {
+	if (frame.tracer != nil) && (len(args) > 0) {
+		frame = frame.traced(f.name(frame.site))
+		frame.tracer.Enter(frame, f, args)
+		defer func() {
+			frame.tracer.Exit(frame, f, args, r)
+		}()
+	}
+
 	defer func() {
 		if err, ok := recover().(error); ok {
-			r = Error{
+			e := Error{
 				Err: err,
 
 				// Hmmm...
 				Frame: frame.Sub("panic in GoFunc"),
 			}
+			if frame.tracer != nil {
+				frame.tracer.Error(e.Frame, e)
+			}
+			r = e
 		}
 	}()
 
@@ -431,14 +636,21 @@ func (f GoFunc) String() string {
 type FuncCall struct {
 	Func Func
 	Args []Func
+
+	// Pos is the position of the call in the script's source.
+	Pos Pos
 }
 
 func (f FuncCall) Call(frame Frame, args ...Func) Func {
 	if err := frame.Context().Err(); err != nil {
-		return &Error{
+		e := &Error{
 			Frame: frame,
 			Err:   err,
 		}
+		if frame.tracer != nil {
+			frame.tracer.Error(frame, e)
+		}
+		return e
 	}
 
 	next := make([]Func, len(f.Args))
@@ -446,7 +658,34 @@ func (f FuncCall) Call(frame Frame, args ...Func) Func {
 		next[i] = f.Args[i].Call(frame)
 	}
 
-	return f.Func.Call(frame).Call(frame, next...)
+	fn := f.Func.Call(frame)
+	if frame.tracer == nil {
+		return fn.Call(frame, next...)
+	}
+
+	r := fn.Call(frame.at(f.Pos, f.Func, fn), next...)
+	f.traceError(frame, fn, next, r)
+	return r
+}
+
+// traceError reports r to the frame's tracer if it is an error that
+// originated in the call, rather than being either the function
+// itself or one of the arguments.
+func (f FuncCall) traceError(frame Frame, fn Func, args []Func, r Func) {
+	err, ok := r.(error)
+	if !ok {
+		return
+	}
+	if _, ok := fn.(error); ok {
+		return
+	}
+	for _, arg := range args {
+		if _, ok := arg.(error); ok {
+			return
+		}
+	}
+
+	frame.tracer.Error(frame, err)
 }
 
 func (f FuncCall) String() string {
@@ -471,6 +710,9 @@ type ChainPiece struct {
 
 	Flags uint
 	Slots Assigner
+
+	// Pos is the position of the piece in the script's source.
+	Pos Pos
 }
 
 func (p ChainPiece) Call(frame Frame, args ...Func) Func {
@@ -498,7 +740,11 @@ func (f Chain) Call(frame Frame, args ...Func) Func {
 
 		tmp := cur.Call(frame.WithScope(frame.Scope().Sub(slotScope)))
 		if prev != nil {
-			tmp = tmp.Call(frame.WithScope(frame.Scope().Sub(slotScope)), prev)
+			next := frame.WithScope(frame.Scope().Sub(slotScope))
+			if frame.tracer != nil {
+				next = next.at(cur.Pos, cur.Expr, tmp)
+			}
+			tmp = tmp.Call(next, prev)
 		}
 
 		if cur.Slots != nil {
@@ -549,6 +795,8 @@ func (sub Sub) Call(frame Frame, args ...Func) Func {
 			return next
 		case *Scope:
 			scope = tmp
+		case Scoper:
+			scope = tmp.Scope()
 		default:
 			return Error{
 				Err:   fmt.Errorf("Function called on non-scope %#v", next),
@@ -638,7 +886,12 @@ func (s Switch) Call(frame Frame, args ...Func) Func {
 			return lhs
 		}
 
-		if lhs.Call(frame, check) == Bool(true) {
+		next := frame
+		if frame.tracer != nil {
+			next = frame.at(PosOf(c[0]), c[0], lhs)
+		}
+
+		if lhs.Call(next, check) == Bool(true) {
 			return c[1].Call(frame)
 		}
 	}
@@ -652,16 +905,69 @@ type Var ID
 
 func (v Var) Call(frame Frame, args ...Func) Func {
 	f := frame.Scope().Get(ID(v))
+	if frame.tracer != nil {
+		frame.tracer.Lookup(frame, ID(v), f)
+	}
 	if f == nil {
-		return &Error{
+		err := &Error{
 			Err:   fmt.Errorf("%q is not in scope", v),
 			Frame: frame,
 		}
+		if frame.tracer != nil {
+			frame.tracer.Error(frame, err)
+		}
+		return err
 	}
 
 	return f.Call(frame, args...)
 }
 
+// An Import is an import expression whose module is determined at
+// runtime, such as
+//
+//    import name
+//
+// When called, it evaluates Expr, which must result in a string, and
+// imports the module with that name using the frame's importer. The
+// module is then called with the given arguments. Imports of string
+// literals, such as import 'stream', are resolved when a script is
+// parsed instead.
+type Import struct {
+	Expr Func
+}
+
+func (i Import) Call(frame Frame, args ...Func) Func {
+	frame = frame.Sub("import")
+
+	from := i.Expr.Call(frame)
+	if _, ok := from.(error); ok {
+		return from
+	}
+
+	name, ok := from.(String)
+	if !ok {
+		return &Error{
+			Err:   fmt.Errorf("can't import %v: not a string", from),
+			Frame: frame,
+		}
+	}
+
+	im := frame.Importer()
+	if im == nil {
+		return &Error{
+			Err:   fmt.Errorf("can't import %q: no importer available", name),
+			Frame: frame,
+		}
+	}
+
+	s, err := im.Import(string(name))
+	if err != nil {
+		return &Error{Err: err, Frame: frame}
+	}
+
+	return s.Call(frame, args...)
+}
+
 // A Lambda is a closure. When called, it calls its inner expression
 // with itself and its own arguments placed into the scope. In other
 // words, given the lambda
@@ -676,6 +982,10 @@ type Lambda struct {
 	Expr Func
 	Args []Assigner
 
+	// Pos is the position in the script's source that the lambda was
+	// declared at.
+	Pos Pos
+
 	Scope    *Scope
 	Original *Lambda
 }
@@ -703,6 +1013,7 @@ func (lambda *Lambda) Call(frame Frame, args ...Func) Func {
 			ID:   lambda.ID,
 			Expr: lambda.Expr,
 			Args: lambda.Args[len(args):],
+			Pos:  lambda.Pos,
 
 			Scope:    scope,
 			Original: lambda.original(),
@@ -715,7 +1026,24 @@ func (lambda *Lambda) Call(frame Frame, args ...Func) Func {
 
 	original := lambda.original()
 	scope = scope.Add(original.ID, original)
-	return lambda.Expr.Call(frame.WithScope(scope))
+	if frame.tracer == nil {
+		return lambda.Expr.Call(frame.WithScope(scope))
+	}
+
+	// Tracers identify lambdas by the ID of the frame that they're
+	// called in, but a sub-frame is only created when one is attached
+	// so that untraced calls don't pay for it.
+	frame = frame.Sub(original.ID).WithScope(scope).traced(original.ID)
+	return lambda.trace(frame, args)
+}
+
+func (lambda *Lambda) trace(frame Frame, args []Func) (r Func) {
+	frame.tracer.Enter(frame, lambda, args)
+	defer func() {
+		frame.tracer.Exit(frame, lambda, args, r)
+	}()
+
+	return lambda.Expr.Call(frame)
 }
 
 func (lambda *Lambda) String() string {
@@ -874,6 +1202,10 @@ func (a PatternAssigner) String() string {
 type LetAssigner struct {
 	Assigner
 	Expr Func
+
+	// Pos is the position of the let expression in the script's
+	// source.
+	Pos Pos
 }
 
 func (a LetAssigner) Call(frame Frame, args ...Func) Func {
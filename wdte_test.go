@@ -2,18 +2,26 @@ package wdte_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
+	"math/big"
 	"math/rand"
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/DeedleFake/wdte"
 	"github.com/DeedleFake/wdte/scanner"
 	"github.com/DeedleFake/wdte/std"
 	_ "github.com/DeedleFake/wdte/std/arrays"
+	_ "github.com/DeedleFake/wdte/std/bytes"
 	_ "github.com/DeedleFake/wdte/std/debug"
 	wdteio "github.com/DeedleFake/wdte/std/io"
 	_ "github.com/DeedleFake/wdte/std/math"
@@ -21,6 +29,7 @@ import (
 	"github.com/DeedleFake/wdte/std/stream"
 	_ "github.com/DeedleFake/wdte/std/stream"
 	_ "github.com/DeedleFake/wdte/std/strings"
+	_ "github.com/DeedleFake/wdte/std/sync"
 )
 
 type test struct {
@@ -156,6 +165,49 @@ func TestBasics(t *testing.T) {
 		}
 	})
 
+	t.Run("Hash", func(t *testing.T) {
+		equal := [][2]wdte.Func{
+			{wdte.Number(3), wdte.Number(3)},
+			{wdte.Number(-0.0), wdte.Number(0)},
+			{wdte.Int(3), wdte.Number(3)},
+			{wdte.Int(-3), wdte.BigInt{Int: big.NewInt(-3)}},
+			{wdte.BigInt{Int: new(big.Int).Lsh(big.NewInt(1), 70)}, wdte.Number(1 << 70)},
+			{wdte.BigRat{Rat: big.NewRat(1, 2)}, wdte.Number(0.5)},
+			{wdte.BigRat{Rat: big.NewRat(6, 2)}, wdte.Int(3)},
+			{wdte.String("test"), wdte.String("test")},
+			{wdte.Bool(true), wdte.Bool(true)},
+			{
+				wdte.Array{wdte.Number(1), wdte.Array{wdte.String("a")}},
+				wdte.Array{wdte.Number(1), wdte.Array{wdte.String("a")}},
+			},
+			{
+				wdte.S().Add("x", wdte.Number(1)).Add("y", wdte.String("y")),
+				wdte.S().Add("y", wdte.String("y")).Add("x", wdte.Number(1)),
+			},
+		}
+
+		for _, pair := range equal {
+			if !wdte.Equal(pair[0], pair[1]) {
+				t.Errorf("Expected %v to equal %v", pair[0], pair[1])
+			}
+			if h1, h2 := wdte.Hash(pair[0]), wdte.Hash(pair[1]); h1 != h2 {
+				t.Errorf("Hashes of %v and %v differ: %v != %v", pair[0], pair[1], h1, h2)
+			}
+		}
+
+		unequal := [][2]wdte.Func{
+			{wdte.Int(9007199254740993), wdte.Number(9007199254740992)},
+			{wdte.BigInt{Int: new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 70), big.NewInt(1))}, wdte.Number(1 << 70)},
+			{wdte.BigRat{Rat: big.NewRat(1, 10)}, wdte.Number(0.1)},
+		}
+
+		for _, pair := range unequal {
+			if wdte.Equal(pair[0], pair[1]) {
+				t.Errorf("Expected %v not to equal %v", pair[0], pair[1])
+			}
+		}
+	})
+
 	runTests(t, []test{
 		{
 			name:   "Simple",
@@ -222,6 +274,11 @@ func TestBasics(t *testing.T) {
 			script: `let (memo) test [a [b]] c => + a b c; (test [1; [2]] 3; test [1; [2]] 3);`,
 			ret:    wdte.Number(6),
 		},
+		{
+			name:   "Simple/Memo/Array",
+			script: `let (memo) test a => at a 0; [test [1; 2]; test [1; 2]; test [[3]; 4]; test [[3]; 4]];`,
+			ret:    wdte.Array{wdte.Number(1), wdte.Number(1), wdte.Array{wdte.Number(3)}, wdte.Array{wdte.Number(3)}},
+		},
 		{
 			name:   "Simple/Rev",
 			script: `let (rev) test a b c => [a; b; c]; (test 1 2) 3;`,
@@ -471,11 +528,87 @@ func TestStd(t *testing.T) {
 			script: `! true;`,
 			ret:    wdte.Bool(false),
 		},
+		{
+			name:   "Equals/Bool",
+			script: `[== true true; == true false; == false false];`,
+			ret:    wdte.Array{wdte.Bool(true), wdte.Bool(false), wdte.Bool(true)},
+		},
+		{
+			name:   "Equals/Array",
+			script: `[== [1; [2; 'a']] [1; [2; 'a']]; == [1; 2] [1; 3]; == [1; 2] [1; 2; 3]];`,
+			ret:    wdte.Array{wdte.Bool(true), wdte.Bool(false), wdte.Bool(false)},
+		},
+		{
+			name:   "Equals/Scope",
+			script: `[== (| let x => 3; let y => [1] |) (| let y => [1]; let x => 3 |); == (| let x => 3 |) (| let x => 4 |); == (| let x => 3 |) (| let y => 3 |)];`,
+			ret:    wdte.Array{wdte.Bool(true), wdte.Bool(false), wdte.Bool(false)},
+		},
+		{
+			name:   "Less/Array",
+			script: `[< [1; 2] [1; 3]; < [1; 2] [1; 2]; < [1] [1; 0]; > [2] [1; 5]; <= [1; 2] [1; 2]];`,
+			ret:    wdte.Array{wdte.Bool(true), wdte.Bool(false), wdte.Bool(true), wdte.Bool(true), wdte.Bool(true)},
+		},
+		{
+			name:   "Int",
+			script: `[+ 9007199254740993i 0i; - 5i 7i; * 3i 4i; % -7i 3i; / 6i 2i];`,
+			ret:    wdte.Array{wdte.Int(9007199254740993), wdte.Int(-2), wdte.Int(12), wdte.Int(-1), wdte.Int(3)},
+		},
+		{
+			name:   "Int/Overflow",
+			script: `+ 9223372036854775807i 1i;`,
+			ret:    wdte.BigInt{Int: new(big.Int).Lsh(big.NewInt(1), 63)},
+		},
+		{
+			name:   "Int/Promote",
+			script: `[+ 1i 0.5; + 1i 2n; / 7i 2i; == 3i 3; < 2i 2.5];`,
+			ret: wdte.Array{
+				wdte.Number(1.5),
+				wdte.BigInt{Int: big.NewInt(3)},
+				wdte.BigRat{Rat: big.NewRat(7, 2)},
+				wdte.Bool(true),
+				wdte.Bool(true),
+			},
+		},
+		{
+			name:   "Int/Exact",
+			script: `[== 9007199254740993i 9007199254740992; == 9007199254740992i 9007199254740992; < 9007199254740992 9007199254740993i];`,
+			ret:    wdte.Array{wdte.Bool(false), wdte.Bool(true), wdte.Bool(true)},
+		},
+		{
+			name:   "Int/Index",
+			script: `[at [1; 2; 3] 1i; at 'abc' 2n; at [1; 2; 3] (/ 4i 2i)];`,
+			ret:    wdte.Array{wdte.Number(2), wdte.String("c"), wdte.Number(3)},
+		},
+		{
+			name:   "Int/Index/NotInteger",
+			script: `at [1; 2; 3] (/ 3i 2i) -| 'Failed';`,
+			ret:    wdte.String("Failed"),
+		},
+		{
+			name:   "Int/DivideByZero",
+			script: `/ 1i 0i -| 'Failed';`,
+			ret:    wdte.String("Failed"),
+		},
+		{
+			name:   "BigInt",
+			script: `* 100000000000000000000n 3n;`,
+			ret:    wdte.BigInt{Int: new(big.Int).Mul(new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil), big.NewInt(3))},
+		},
+		{
+			name:   "BigRat",
+			script: `[== (+ 0.1r 0.2r) 0.3r; == (+ 0.1 0.2) 0.3];`,
+			ret:    wdte.Array{wdte.Bool(true), wdte.Bool(false)},
+		},
 		{
 			name:   "Len/String",
 			script: `len 'test';`,
 			ret:    wdte.Number(4),
 		},
+		{
+			name:   "Len/String/Unicode",
+			script: `len 'héllo, 世界';`,
+			ret:    wdte.Number(9),
+		},
 		{
 			name:   "Len/Array",
 			script: `len [3; 5; 1];`,
@@ -491,6 +624,16 @@ func TestStd(t *testing.T) {
 			script: `at 'test' 2;`,
 			ret:    wdte.String("s"),
 		},
+		{
+			name:   "At/String/Unicode",
+			script: `[at 'héllo, 世界' 1; at 'héllo, 世界' 8];`,
+			ret:    wdte.Array{wdte.String("é"), wdte.String("界")},
+		},
+		{
+			name:   "At/String/OutOfRange",
+			script: `at '世界' 2 -| 'Failed';`,
+			ret:    wdte.String("Failed"),
+		},
 		{
 			name:   "At/Array",
 			script: `at [3; 5; 1] 0;`,
@@ -531,6 +674,11 @@ func TestMath(t *testing.T) {
 			script: `let m => import 'math'; m.abs -3;`,
 			ret:    wdte.Number(3),
 		},
+		{
+			name:   "Int",
+			script: `let m => import 'math'; [m.abs -3i; m.floor 2n; m.ceil (/ 3i 2i)];`,
+			ret:    wdte.Array{wdte.Number(3), wdte.Number(2), wdte.Number(2)},
+		},
 		{
 			name:   "Ceil",
 			script: `let m => import 'math'; m.ceil 1.1;`,
@@ -637,6 +785,19 @@ func TestStream(t *testing.T) {
 			script: `let s => import 'stream'; s.range 3 -> s.skip 2 -> s.collect;`,
 			ret:    wdte.Array{wdte.Number(2)},
 		},
+		{
+			name:   "Int",
+			script: `let s => import 'stream'; [s.range 1i 12i 2 -> s.skip 1i -> s.limit 3n -> s.collect; s.range 5 -> s.extent 2i >];`,
+			ret: wdte.Array{
+				wdte.Array{wdte.Number(3), wdte.Number(5), wdte.Number(7)},
+				wdte.Array{wdte.Number(4), wdte.Number(3)},
+			},
+		},
+		{
+			name:   "Limit/NotInteger",
+			script: `let s => import 'stream'; reflect (s.limit 1.5) 'Error';`,
+			ret:    wdte.Bool(true),
+		},
 		{
 			name:   "Zip",
 			script: `let s => import 'stream'; s.zip (s.range 2) (s.range 1 2) -> s.collect;`,
@@ -741,9 +902,102 @@ func TestStream(t *testing.T) {
 			`,
 			ret: wdte.Array{wdte.Bool(true), wdte.Bool(true), wdte.Bool(false)},
 		},
+		{
+			name:   "PMap",
+			script: `let s => import 'stream'; s.range 10 -> s.pmap 4 (* 2) -> s.collect;`,
+			ret:    wdte.Array{wdte.Number(0), wdte.Number(2), wdte.Number(4), wdte.Number(6), wdte.Number(8), wdte.Number(10), wdte.Number(12), wdte.Number(14), wdte.Number(16), wdte.Number(18)},
+		},
+		{
+			name: "PMap/Concurrent",
+			script: `let s => import 'stream';
+let sync => import 'sync';
+let c => sync.chan 0;
+s.range 2
+-> s.pmap 2 (@ f n => n {
+	== 0 => sync.recv c;
+	true => (sync.send c 'x'; 'y');
+})
+-> s.collect;`,
+			ret: wdte.Array{wdte.String("x"), wdte.String("y")},
+		},
+		{
+			name:   "PMap/Error",
+			script: `let s => import 'stream'; reflect (s.range 100 -> s.pmap 4 (@ f n => n { == 5 => at [] 0; true => n }) -> s.collect) 'Error';`,
+			ret:    wdte.Bool(true),
+		},
+		{
+			name: "PMap/Error/Cancel",
+			script: `let s => import 'stream';
+let sync => import 'sync';
+let io => import 'io';
+let c => sync.chan 0;
+s.range 2
+-> s.pmap 2 (@ f n => n {
+	== 0 => sync.recv c;
+	true => at [] 0;
+})
+-> s.collect
+-| io.panic io.stderr 'Failed'
+-| 0;`,
+			err: "Failed: index 0 is out of range [0,0)\n",
+		},
+		{
+			name:   "PMap/Infinite",
+			script: `let s => import 'stream'; s.new 0 (+ 1) -> s.pmap 4 (* 2) -> s.limit 5 -> s.collect;`,
+			ret:    wdte.Array{wdte.Number(0), wdte.Number(2), wdte.Number(4), wdte.Number(6), wdte.Number(8)},
+		},
+		{
+			name:   "PMap/Int",
+			script: `let s => import 'stream'; s.range 4 -> s.pmap 2i (* 2) -> s.collect;`,
+			ret:    wdte.Array{wdte.Number(0), wdte.Number(2), wdte.Number(4), wdte.Number(6)},
+		},
+		{
+			name:   "PMapUnordered",
+			script: `let s => import 'stream'; let a => import 'arrays'; s.range 10 -> s.pmapUnordered 4 (* 2) -> s.collect -> a.sort <;`,
+			ret:    wdte.Array{wdte.Number(0), wdte.Number(2), wdte.Number(4), wdte.Number(6), wdte.Number(8), wdte.Number(10), wdte.Number(12), wdte.Number(14), wdte.Number(16), wdte.Number(18)},
+		},
+		{
+			name:   "PFilter",
+			script: `let s => import 'stream'; s.range 10 -> s.pfilter 3 (@ f n => == (% n 2) 0) -> s.collect;`,
+			ret:    wdte.Array{wdte.Number(0), wdte.Number(2), wdte.Number(4), wdte.Number(6), wdte.Number(8)},
+		},
+		{
+			name:   "PFilterUnordered",
+			script: `let s => import 'stream'; let a => import 'arrays'; s.range 10 -> s.pfilterUnordered 3 (@ f n => == (% n 2) 1) -> s.collect -> a.sort <;`,
+			ret:    wdte.Array{wdte.Number(1), wdte.Number(3), wdte.Number(5), wdte.Number(7), wdte.Number(9)},
+		},
 	})
 }
 
+func TestParallelStreamLeak(t *testing.T) {
+	scripts := []string{
+		`let s => import 'stream'; s.new 0 (+ 1) -> s.pmap 8 (* 2) -> s.limit 5 -> s.collect;`,
+		`let s => import 'stream'; s.range 1000 -> s.pmapUnordered 8 (* 2) -> s.any (== 4);`,
+		`let s => import 'stream'; s.range 1000 -> s.pfilter 8 (< 500) -> s.all (< 3);`,
+	}
+
+	before := runtime.NumGoroutine()
+	for _, script := range scripts {
+		m, err := wdte.Parse(strings.NewReader(script), std.Import, nil)
+		if err != nil {
+			t.Fatalf("Failed to parse script: %v", err)
+		}
+		for i := 0; i < 20; i++ {
+			if err, ok := m.Call(std.F()).(error); ok {
+				t.Fatalf("Script returned an error: %v", err)
+			}
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("Goroutines leaked: %v before, %v after", before, runtime.NumGoroutine())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
 func TestIO(t *testing.T) {
 	runTests(t, []test{
 		{
@@ -761,6 +1015,35 @@ func TestIO(t *testing.T) {
 			script: `let io => import 'io'; + a b -| io.panic io.stderr 'Failed to add a and b' -| 3;`,
 			err:    `Failed to add a and b: "a" is not in scope` + "\n",
 		},
+		{
+			name:   "Bytes",
+			script: `let io => import 'io'; let main => io.stdin -> io.bytes;`,
+			in:     "\xff\x00abc",
+			ret:    wdte.Bytes{0xFF, 0x00, 'a', 'b', 'c'},
+		},
+		{
+			name:   "Read",
+			script: `let io => import 'io'; let main => [io.read io.stdin 2; io.read io.stdin 5; io.read io.stdin 1];`,
+			in:     "abcd",
+			ret:    wdte.Array{wdte.Bytes("ab"), wdte.Bytes("cd"), wdte.Bytes{}},
+		},
+		{
+			name:   "Read/Negative",
+			script: `let io => import 'io'; io.read io.stdin -1 -| io.panic io.stderr 'Failed to read' -| 0;`,
+			in:     "abc",
+			err:    "Failed to read: negative read size: -1\n",
+		},
+		{
+			name:   "Read/Int",
+			script: `let io => import 'io'; io.read io.stdin 2i;`,
+			in:     "abcd",
+			ret:    wdte.Bytes("ab"),
+		},
+		{
+			name:   "Write/Bytes",
+			script: `let io => import 'io'; let b => import 'bytes'; b.fromHex '61ff62' -> io.writeln io.stdout;`,
+			out:    "a\xffb\n",
+		},
 		{
 			name:   "Lines",
 			script: `let io => import 'io'; let s => import 'stream'; let str => import 'strings'; let main v => str.read v -> io.lines -> s.collect;`,
@@ -798,6 +1081,21 @@ func TestStrings(t *testing.T) {
 			script: `let a => import 'arrays'; let s => import 'stream'; let str => import 'strings'; let main => a.stream ['abcde'; 'bcdef'; 'cdefg'; 'defgh'; 'efghi'] -> s.map (str.index 'cd') -> s.collect;`,
 			ret:    wdte.Array{wdte.Number(2), wdte.Number(1), wdte.Number(0), wdte.Number(-1), wdte.Number(-1)},
 		},
+		{
+			name:   "Index/Unicode",
+			script: `let str => import 'strings'; str.index '世界, hello' 'hello';`,
+			ret:    wdte.Number(4),
+		},
+		{
+			name:   "Runes",
+			script: `let str => import 'strings'; str.runes 'a世b';`,
+			ret:    wdte.Array{wdte.String("a"), wdte.String("世"), wdte.String("b")},
+		},
+		{
+			name:   "Bytes",
+			script: `let str => import 'strings'; let b => str.bytes 'a世'; [len b; at b 0];`,
+			ret:    wdte.Array{wdte.Number(4), wdte.Number('a')},
+		},
 		{
 			name:   "Upper",
 			script: `let str => import 'strings'; let main => str.upper 'QwErTy';`,
@@ -813,6 +1111,20 @@ func TestStrings(t *testing.T) {
 			script: `let str => import 'strings'; str.repeat 'test' 3;`,
 			ret:    wdte.String("testtesttest"),
 		},
+		{
+			name:   "Repeat/Int",
+			script: `let str => import 'strings'; [str.repeat 'ab' 2i; str.repeat 2n 'ab'];`,
+			ret:    wdte.Array{wdte.String("abab"), wdte.String("abab")},
+		},
+		{
+			name:   "Split/Int",
+			script: `let str => import 'strings'; [str.split 'a b c' ' ' 2i; (str.split ' ' 2i) 'a b c'; (str.split ' ') 'a b c' 2i];`,
+			ret: wdte.Array{
+				wdte.Array{wdte.String("a"), wdte.String("b c")},
+				wdte.Array{wdte.String("a"), wdte.String("b c")},
+				wdte.Array{wdte.String("a"), wdte.String("b c")},
+			},
+		},
 		{
 			name:   "Split",
 			script: `let str => import 'strings'; [str.split 'a test' ' '; str.split 'this is a test' ' ' 2; (str.split ' ') 'this is also a test' 3; (str.split ' ' 2) 'or is it'];`,
@@ -871,6 +1183,179 @@ func TestStrings(t *testing.T) {
 	})
 }
 
+func TestBytes(t *testing.T) {
+	runTests(t, []test{
+		{
+			name:   "FromString",
+			script: `let b => import 'bytes'; let s => b.fromString 'héllo'; [len s; at s 1; b.string s];`,
+			ret:    wdte.Array{wdte.Number(6), wdte.Number(0xC3), wdte.String("héllo")},
+		},
+		{
+			name:   "Slice",
+			script: `let b => import 'bytes'; b.fromString 'abcdef' -> b.slice 1 4 -> b.string;`,
+			ret:    wdte.String("bcd"),
+		},
+		{
+			name:   "Slice/Int",
+			script: `let b => import 'bytes'; b.fromString 'abcdef' -> b.slice 1i 4n -> b.string;`,
+			ret:    wdte.String("bcd"),
+		},
+		{
+			name:   "Concat",
+			script: `let b => import 'bytes'; b.concat (b.fromString 'ab') 'cd' (b.fromHex '00ff');`,
+			ret:    wdte.Bytes{'a', 'b', 'c', 'd', 0x00, 0xFF},
+		},
+		{
+			name:   "Index",
+			script: `let b => import 'bytes'; [b.index (b.fromString 'abcdef') 'cd'; b.fromString 'abc' -> b.index (b.fromString 'z')];`,
+			ret:    wdte.Array{wdte.Number(2), wdte.Number(-1)},
+		},
+		{
+			name:   "Hex",
+			script: `let b => import 'bytes'; [b.hex (b.fromString 'hi'); b.fromHex '6869' -> b.string];`,
+			ret:    wdte.Array{wdte.String("6869"), wdte.String("hi")},
+		},
+		{
+			name:   "Base64",
+			script: `let b => import 'bytes'; [b.base64 (b.fromString 'hi'); b.fromBase64 'aGk=' -> b.string];`,
+			ret:    wdte.Array{wdte.String("aGk="), wdte.String("hi")},
+		},
+		{
+			name:   "Set",
+			script: `let b => import 'bytes'; set (b.fromString 'abc') 1 0 -> b.hex;`,
+			ret:    wdte.String("610063"),
+		},
+		{
+			name:   "Set/Invalid",
+			script: `let b => import 'bytes'; let s => b.fromString 'abc'; [reflect (set s 1 1.5) 'Error'; reflect (set s 1 256) 'Error'; reflect (set s 1 (* 100000000000 100000000000)) 'Error'; reflect (set s 1 -1) 'Error'];`,
+			ret:    wdte.Array{wdte.Bool(true), wdte.Bool(true), wdte.Bool(true), wdte.Bool(true)},
+		},
+		{
+			name:   "Compare",
+			script: `let b => import 'bytes'; [== (b.fromHex '0102') (b.fromHex '0102'); < (b.fromHex '01') (b.fromHex '02')];`,
+			ret:    wdte.Array{wdte.Bool(true), wdte.Bool(true)},
+		},
+	})
+}
+
+func TestSync(t *testing.T) {
+	t.Run("Context", func(t *testing.T) {
+		t.Parallel()
+
+		const script = `let sync => import 'sync'; let c => sync.chan 0; sync.spawn sync.recv c -> sync.await;`
+		m, err := wdte.Parse(strings.NewReader(script), std.Import, nil)
+		if err != nil {
+			t.Fatalf("Failed to parse script: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		ret := m.Call(std.F().WithContext(ctx))
+		if err, ok := ret.(error); !ok || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected deadline exceeded error, but got %v", ret)
+		}
+	})
+
+	runTests(t, []test{
+		{
+			name:   "Spawn",
+			script: `let sync => import 'sync'; sync.spawn (@ double n => * n 2) 21 -> sync.await;`,
+			ret:    wdte.Number(42),
+		},
+		{
+			name:   "Cancel",
+			script: `let sync => import 'sync'; let c => sync.chan 0; let t => sync.spawn sync.recv c; reflect (sync.await (sync.cancel t)) 'Error';`,
+			ret:    wdte.Bool(true),
+		},
+		{
+			name: "Chan",
+			script: `let sync => import 'sync';
+let s => import 'stream';
+
+let c => sync.chan 0;
+let produce c => c -> sync.send 1 -> sync.send 2 -> sync.send 3 -> sync.close;
+let t => sync.spawn produce c;
+c -> s.map (* 2) -> s.collect;`,
+			ret: wdte.Array{wdte.Number(2), wdte.Number(4), wdte.Number(6)},
+		},
+		{
+			name:   "Recv",
+			script: `let sync => import 'sync'; let c => sync.chan 1; c -> sync.send 5 -> sync.close; [sync.recv c; sync.recv c];`,
+			ret:    wdte.Array{wdte.Number(5), stream.End()},
+		},
+		{
+			name:   "Chan/Int",
+			script: `let sync => import 'sync'; let c => sync.chan 1i; c -> sync.send 5 -> sync.close; sync.recv c;`,
+			ret:    wdte.Number(5),
+		},
+		{
+			name:   "Chan/Negative",
+			script: `let io => import 'io'; let sync => import 'sync'; sync.chan -1 -| io.panic io.stderr 'Failed to make channel' -| 0;`,
+			err:    "Failed to make channel: negative buffer size: -1\n",
+		},
+		{
+			name:   "Send/Closed",
+			script: `let io => import 'io'; let sync => import 'sync'; sync.chan 1 -> sync.close -> sync.send 5 -| io.panic io.stderr 'Failed to send' -| 0;`,
+			err:    "Failed to send: send on closed channel\n",
+		},
+		{
+			name: "Send/Waiting",
+			script: `let sync => import 'sync';
+let c => sync.chan 0;
+let t => sync.spawn (sync.send 5) c;
+sync.close c;
+reflect (sync.await t) 'Error';`,
+			ret: wdte.Bool(true),
+		},
+		{
+			name:   "Close/Closed",
+			script: `let io => import 'io'; let sync => import 'sync'; sync.chan 0 -> sync.close -> sync.close -| io.panic io.stderr 'Failed to close' -| 0;`,
+			err:    "Failed to close: close of closed channel\n",
+		},
+		{
+			name: "Select",
+			script: `let sync => import 'sync';
+let c1 => sync.chan 1;
+let c2 => sync.chan 1;
+c2 -> sync.send 'two';
+sync.select [
+	[c1; (@ one v => 'one')];
+	[c2; (@ two v => v)];
+];`,
+			ret: wdte.String("two"),
+		},
+		{
+			name:   "Select/Task",
+			script: `let sync => import 'sync'; let t => sync.spawn (+ 2) 1; sync.select [[sync.chan 0; (@ c v => 0)]; [t; (@ t v => * v 2)]];`,
+			ret:    wdte.Number(6),
+		},
+		{
+			name:   "WaitAll",
+			script: `let sync => import 'sync'; let double n => * n 2; sync.waitAll [sync.spawn double 1; sync.spawn double 2; sync.spawn double 3];`,
+			ret:    wdte.Array{wdte.Number(2), wdte.Number(4), wdte.Number(6)},
+		},
+		{
+			name: "WaitAll/Error",
+			script: `let sync => import 'sync';
+let c => sync.chan 0;
+let blocked => sync.spawn sync.recv c;
+let r => sync.waitAll [blocked; sync.spawn (at [1]) 5];
+[reflect r 'Error'; reflect (sync.await blocked) 'Error'];`,
+			ret: wdte.Array{wdte.Bool(true), wdte.Bool(true)},
+		},
+		{
+			name: "WaitAny",
+			script: `let sync => import 'sync';
+let c => sync.chan 0;
+let r => sync.waitAny [sync.spawn sync.recv c; sync.spawn (* 2) 2];
+sync.close c;
+r;`,
+			ret: wdte.Number(4),
+		},
+	})
+}
+
 func TestArrays(t *testing.T) {
 	runTests(t, []test{
 		{
@@ -883,6 +1368,16 @@ func TestArrays(t *testing.T) {
 			script: `let a => import 'arrays'; a.sort [5; 3; 7] <;`,
 			ret:    wdte.Array{wdte.Number(3), wdte.Number(5), wdte.Number(7)},
 		},
+		{
+			name:   "Sort/Arrays",
+			script: `let a => import 'arrays'; a.sort [[2; 1]; [1; 3]; [1; 2; 0]; [1]] <;`,
+			ret: wdte.Array{
+				wdte.Array{wdte.Number(1)},
+				wdte.Array{wdte.Number(1), wdte.Number(2), wdte.Number(0)},
+				wdte.Array{wdte.Number(1), wdte.Number(3)},
+				wdte.Array{wdte.Number(2), wdte.Number(1)},
+			},
+		},
 		{
 			name: "SortStable",
 			script: `
@@ -932,6 +1427,11 @@ func TestRand(t *testing.T) {
 			script: `let s => import 'stream'; let m => import 'math'; let rand => import 'rand'; rand.gen 1 -> rand.stream 3 -> s.map (* 100) -> s.map m.floor -> s.collect;`,
 			ret:    wdte.Array{wdte.Number(60), wdte.Number(94), wdte.Number(66)},
 		},
+		{
+			name:   "Stream/Int",
+			script: `let s => import 'stream'; let m => import 'math'; let rand => import 'rand'; rand.gen 1i -> rand.stream 3i -> s.map (* 100) -> s.map m.floor -> s.collect;`,
+			ret:    wdte.Array{wdte.Number(60), wdte.Number(94), wdte.Number(66)},
+		},
 	})
 }
 
@@ -957,6 +1457,683 @@ func TestDebug(t *testing.T) {
 	})
 }
 
+func TestImport(t *testing.T) {
+	const script = `let name => 'stream'; let s => import name; s.range 3 -> s.collect;`
+
+	m, err := wdte.Parse(strings.NewReader(script), std.Import, nil)
+	if err != nil {
+		t.Fatalf("Failed to parse script: %v", err)
+	}
+
+	t.Run("Runtime", func(t *testing.T) {
+		ret := m.Call(std.F().WithImporter(std.Import))
+		expected := wdte.Array{wdte.Number(0), wdte.Number(1), wdte.Number(2)}
+		if !wdte.Equal(ret, expected) {
+			t.Errorf("Expected %v, but got %v", expected, ret)
+		}
+	})
+
+	t.Run("NoImporter", func(t *testing.T) {
+		ret := m.Call(std.F())
+		if _, ok := ret.(error); !ok {
+			t.Errorf("Expected an error, but got %v", ret)
+		}
+	})
+
+	t.Run("NotString", func(t *testing.T) {
+		m, err := wdte.Parse(strings.NewReader(`let name => 3; import name;`), std.Import, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ret := m.Call(std.F().WithImporter(std.Import))
+		if _, ok := ret.(error); !ok {
+			t.Errorf("Expected an error, but got %v", ret)
+		}
+	})
+
+	t.Run("Cache", func(t *testing.T) {
+		var imports int
+		im := wdte.ImportFunc(func(from string) (*wdte.Scope, error) {
+			imports++
+			return wdte.S().Add("v", wdte.String(from)), nil
+		})
+
+		m, err := wdte.Parse(strings.NewReader(`let name => 'mod'; [(import name).v; (import name).v];`), std.Import, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ret := m.Call(std.F().WithImporter(im))
+		expected := wdte.Array{wdte.String("mod"), wdte.String("mod")}
+		if !wdte.Equal(ret, expected) {
+			t.Errorf("Expected %v, but got %v", expected, ret)
+		}
+		if imports != 1 {
+			t.Errorf("Expected module to be imported once, but it was imported %v times", imports)
+		}
+	})
+}
+
+func TestExport(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		known   []wdte.ID
+		invalid bool
+	}{
+		{"All", `let a => 1; let b => 2;`, []wdte.ID{"a", "b"}, false},
+		{"NoExports", `let module => (| let name => 'm'; |); let a => 1;`, []wdte.ID{"a"}, false},
+		{"Exports", `let module => (| let exports => ['a']; |); let a => 1; let b => 2;`, []wdte.ID{"a"}, false},
+		{"Missing", `let module => (| let exports => ['c']; |); let a => 1;`, nil, true},
+		{"NotScope", `let module => 'm';`, nil, true},
+		{"BadName", `let module => (| let name => 3; |);`, nil, true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			m, err := wdte.Parse(strings.NewReader(test.script), std.Import, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			frame := std.F()
+			s, _ := m.Collect(frame)
+			s, err = wdte.Export(frame, s)
+			if test.invalid {
+				if err == nil {
+					t.Errorf("Expected an error, but got %v", s)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if known := s.Known(); !reflect.DeepEqual(known, test.known) {
+				t.Errorf("Expected %v, but got %v", test.known, known)
+			}
+		})
+	}
+}
+
+func TestProgram(t *testing.T) {
+	const script = `
+let s => import 'stream';
+let scale => * factor;
+let sum n => s.range n -> s.map scale -> s.reduce 0 +;
+let forever n => s.new n (+ 1) -> s.drain;
+sum 3;
+`
+
+	var imports int
+	im := wdte.ImportFunc(func(from string) (*wdte.Scope, error) {
+		imports++
+		return std.Import.Import(from)
+	})
+
+	p, err := wdte.Compile(strings.NewReader(script), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := std.F().WithImporter(im)
+	for _, factor := range []wdte.Number{1, 2, 3} {
+		inst, err := p.Instantiate(base.WithScope(std.Scope.Add("factor", factor)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ret := inst.Result(); !wdte.Equal(ret, 3*factor) {
+			t.Errorf("Expected result %v, but got %v", 3*factor, ret)
+		}
+		if ret := inst.Call("sum", wdte.Number(4)); !wdte.Equal(ret, 6*factor) {
+			t.Errorf("Expected sum %v, but got %v", 6*factor, ret)
+		}
+		if inst.Lookup("sum") == nil {
+			t.Errorf("Lookup failed to find sum")
+		}
+	}
+	if imports != 1 {
+		t.Errorf("Expected one import, but got %v", imports)
+	}
+
+	inst, err := p.Instantiate(base.WithScope(std.Scope.Add("factor", wdte.Number(1))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Undefined", func(t *testing.T) {
+		if inst.Lookup("missing") != nil {
+			t.Errorf("Lookup found missing variable")
+		}
+		if _, ok := inst.Call("missing").(error); !ok {
+			t.Errorf("Expected an error when calling a missing variable")
+		}
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		ret := inst.CallContext(ctx, "forever", wdte.Number(0))
+		if err, ok := ret.(error); !ok || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected deadline exceeded error, but got %v", ret)
+		}
+	})
+
+	t.Run("NoImporter", func(t *testing.T) {
+		_, err := p.Instantiate(std.F().WithScope(std.Scope.Add("factor", wdte.Number(1))))
+		if err == nil {
+			t.Errorf("Expected an error without an importer")
+		}
+	})
+}
+
+func TestProgramEncoding(t *testing.T) {
+	const script = `
+let s => import 'stream';
+let a => import 'arrays';
+let name => 'strings';
+let str => import name;
+
+let (memo) fib n => n {
+	<= 1 => n;
+	true => + (fib (- n 1)) (fib (- n 2));
+};
+
+let [x [y z]] => [1; [2; 3]];
+let m => (| let v => 3.5; |);
+let sum => s.range 4 -> s.reduce 0 + : total -- s.range 2 -| 0 -> + total;
+
+[
+	fib 10;
+	+ x (+ y z);
+	m.v;
+	sum;
+	(@ f n => * n 2) 3;
+	a.stream [1; 2] -> s.map (+ 1) -> s.collect;
+	str.upper 'encoded';
+	12345678901234567890n;
+	1.25r;
+	3i;
+];
+`
+
+	p, err := wdte.Compile(strings.NewReader(script), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded wdte.Program
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := decoded.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, again) {
+		t.Errorf("Re-encoding decoded program produced different data")
+	}
+
+	frame := std.F().WithImporter(std.Import)
+	expected, err := p.Instantiate(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inst, err := decoded.Instantiate(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wdte.Equal(inst.Result(), expected.Result()) {
+		t.Errorf("Expected %v, but got %v", expected.Result(), inst.Result())
+	}
+	for i, v := range inst.Result().(wdte.Array) {
+		if _, ok := v.(error); ok {
+			t.Errorf("Element %v of result is an error: %v", i, v)
+		}
+	}
+
+	t.Run("Version", func(t *testing.T) {
+		bad := append([]byte(nil), data...)
+		bad[len("WDTEC")]++
+
+		err := new(wdte.Program).UnmarshalBinary(bad)
+		if !errors.Is(err, wdte.ErrProgramFormat) {
+			t.Errorf("Expected format error, but got %v", err)
+		}
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		for i := len("WDTEC") + 1; i < len(data); i++ {
+			if err := new(wdte.Program).UnmarshalBinary(data[:i]); err == nil {
+				t.Fatalf("Decoding truncated program of length %v succeeded", i)
+			}
+		}
+	})
+}
+
+type infiniteReader struct{}
+
+func (infiniteReader) Read(buf []byte) (int, error) {
+	for i := range buf {
+		buf[i] = '\n'
+	}
+	return len(buf), nil
+}
+
+type testTracer struct {
+	events []string
+}
+
+func (t *testTracer) Enter(frame wdte.Frame, f wdte.Func, args []wdte.Func) {
+	if _, ok := f.(*wdte.Lambda); ok {
+		t.events = append(t.events, fmt.Sprintf("enter %v %v", frame.ID(), args))
+	}
+}
+
+func (t *testTracer) Exit(frame wdte.Frame, f wdte.Func, args []wdte.Func, r wdte.Func) {
+	if _, ok := f.(*wdte.Lambda); ok {
+		t.events = append(t.events, fmt.Sprintf("exit %v %v", frame.ID(), r))
+	}
+}
+
+func (t *testTracer) Lookup(frame wdte.Frame, id wdte.ID, f wdte.Func) {
+	t.events = append(t.events, fmt.Sprintf("lookup %v %v", id, f != nil))
+}
+
+func (t *testTracer) Error(frame wdte.Frame, err error) {
+	t.events = append(t.events, fmt.Sprintf("error %v", err))
+}
+
+// callTracer records the traced calls made, along with the calls
+// that they were made during.
+type callTracer struct {
+	wdte.NopTracer
+	calls []string
+}
+
+func (t *callTracer) Enter(frame wdte.Frame, f wdte.Func, args []wdte.Func) {
+	c := frame.TracedCall()
+
+	names := make([]string, c.Depth+1)
+	for p := c; p != nil; p = p.Parent {
+		names[p.Depth] = string(p.Name)
+	}
+	t.calls = append(t.calls, fmt.Sprintf("%v %v line %v", strings.Join(names, " > "), args, c.Pos.Line))
+}
+
+// exitTracer records the values passed to Exit for Go functions.
+type exitTracer struct {
+	wdte.NopTracer
+	exits []wdte.Func
+}
+
+func (t *exitTracer) Exit(frame wdte.Frame, f wdte.Func, args []wdte.Func, r wdte.Func) {
+	if _, ok := f.(wdte.GoFunc); ok {
+		t.exits = append(t.exits, r)
+	}
+}
+
+// crashFunc panics with a value that isn't an error when it's called
+// with arguments. Unlike a GoFunc, nothing recovers from the panic.
+type crashFunc struct{}
+
+func (f crashFunc) Call(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	if len(args) == 0 {
+		return f
+	}
+	panic("crashed")
+}
+
+func TestTracer(t *testing.T) {
+	var fail, explode wdte.GoFunc
+	fail = func(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+		if len(args) == 0 {
+			return fail
+		}
+		return wdte.Error{Err: errors.New("failed"), Frame: frame}
+	}
+	explode = func(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+		if len(args) == 0 {
+			return explode
+		}
+		panic(errors.New("exploded"))
+	}
+
+	scope := std.Scope.Map(map[wdte.ID]wdte.Func{
+		"fail":    fail,
+		"explode": explode,
+	})
+
+	tests := []struct {
+		name   string
+		script string
+		events []string
+	}{
+		{
+			name:   "Lambda",
+			script: `let double n => * n 2; double 3;`,
+			events: []string{
+				"lookup double true",
+				"enter double [3]",
+				"lookup n true",
+				"lookup * true",
+				"exit double 6",
+			},
+		},
+		{
+			name:   "Missing",
+			script: `nope 3;`,
+			events: []string{
+				"lookup nope false",
+				`error "nope" is not in scope`,
+			},
+		},
+		{
+			name:   "Error",
+			script: `let f x => fail x; + 1 (f 2);`,
+			events: []string{
+				"lookup f true",
+				"enter f [2]",
+				"lookup x true",
+				"lookup fail true",
+				"error failed",
+				"exit f failed",
+				"error failed",
+				"lookup + true",
+			},
+		},
+		{
+			name:   "Panic",
+			script: `explode 1;`,
+			events: []string{
+				"lookup explode true",
+				"error exploded",
+				"error exploded",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, err := wdte.Parse(strings.NewReader(test.script), std.Import, nil)
+			if err != nil {
+				t.Fatalf("Failed to parse script: %v", err)
+			}
+
+			var tracer testTracer
+			m.Call(std.F().WithScope(scope).WithTracer(&tracer))
+			if !reflect.DeepEqual(tracer.events, test.events) {
+				t.Errorf("Events:\n\t%q\nExpected:\n\t%q", tracer.events, test.events)
+			}
+		})
+	}
+
+	t.Run("Calls", func(t *testing.T) {
+		const script = `let s => import 'stream';
+let double n =>
+	* n 2;
+s.range 2
+-> s.map double
+-> s.collect;
+`
+
+		m, err := wdte.Parse(strings.NewReader(script), std.Import, nil)
+		if err != nil {
+			t.Fatalf("Failed to parse script: %v", err)
+		}
+
+		var tracer callTracer
+		m.Call(std.F().WithTracer(&tracer))
+
+		expected := []string{
+			"s.range [2] line 4",
+			"s.map [(@ double n => ...)] line 5",
+			"s.map [<stream>] line 5",
+			"s.collect [<stream>] line 6",
+			"s.collect > double [0] line 6",
+			"s.collect > double > * [0 2] line 3",
+			"s.collect > double [1] line 6",
+			"s.collect > double > * [1 2] line 3",
+		}
+		if !reflect.DeepEqual(tracer.calls, expected) {
+			t.Errorf("Calls:\n\t%q\nExpected:\n\t%q", tracer.calls, expected)
+		}
+	})
+
+	t.Run("GoFuncPanic", func(t *testing.T) {
+		m, err := wdte.Parse(strings.NewReader(`explode 1;`), std.Import, nil)
+		if err != nil {
+			t.Fatalf("Failed to parse script: %v", err)
+		}
+
+		var tracer exitTracer
+		m.Call(std.F().WithScope(scope).WithTracer(&tracer))
+
+		if len(tracer.exits) != 1 {
+			t.Fatalf("Expected one exit, but got %v", tracer.exits)
+		}
+		r, ok := tracer.exits[0].(wdte.Error)
+		if !ok || (r.Err.Error() != "exploded") {
+			t.Errorf("Expected exploded error, but got %#v", tracer.exits[0])
+		}
+	})
+
+	t.Run("Unwind", func(t *testing.T) {
+		m, err := wdte.Parse(strings.NewReader(`let f x => crash x; f 1;`), std.Import, nil)
+		if err != nil {
+			t.Fatalf("Failed to parse script: %v", err)
+		}
+
+		var tracer testTracer
+		func() {
+			defer func() {
+				if r := recover(); r != "crashed" {
+					t.Errorf("Expected panic, but got %v", r)
+				}
+			}()
+			m.Call(std.F().WithScope(scope.Add("crash", crashFunc{})).WithTracer(&tracer))
+		}()
+
+		expected := []string{
+			"lookup f true",
+			"enter f [1]",
+			"lookup x true",
+			"lookup crash true",
+			"exit f <nil>",
+		}
+		if !reflect.DeepEqual(tracer.events, expected) {
+			t.Errorf("Events:\n\t%q\nExpected:\n\t%q", tracer.events, expected)
+		}
+	})
+
+	t.Run("Nop", func(t *testing.T) {
+		m, err := wdte.Parse(strings.NewReader(`let f x => + x 1; f 2;`), std.Import, nil)
+		if err != nil {
+			t.Fatalf("Failed to parse script: %v", err)
+		}
+
+		r := m.Call(std.F().WithTracer(wdte.NopTracer{}))
+		if r != wdte.Number(3) {
+			t.Errorf("Expected 3, but got %v", r)
+		}
+	})
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+	}{
+		{"Drain", `let s => import 'stream'; s.new 0 (+ 1) -> s.drain;`},
+		{"Collect", `let s => import 'stream'; s.range 3 -> s.repeat -> s.collect;`},
+		{"Reduce", `let s => import 'stream'; s.range 3 -> s.repeat -> s.reduce 0 +;`},
+		{"Filter", `let s => import 'stream'; s.new 0 (+ 1) -> s.filter (@ f n => false) -> s.drain;`},
+		{"Any", `let s => import 'stream'; s.new 0 (+ 1) -> s.any (@ f n => false);`},
+		{"Copy", `let io => import 'io'; io.copy discard inf;`},
+		{"Lines", `let io => import 'io'; let s => import 'stream'; io.lines inf -> s.drain;`},
+		{"Sync", `let sync => import 'sync'; sync.spawn sync.recv (sync.chan 0) -> sync.await;`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := wdte.Parse(strings.NewReader(test.script), std.Import, nil)
+			if err != nil {
+				t.Fatalf("Failed to parse script: %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			scope := std.Scope.Map(map[wdte.ID]wdte.Func{
+				"inf":     wdteio.Reader{Reader: infiniteReader{}},
+				"discard": wdteio.Writer{Writer: ioutil.Discard},
+			})
+			ret := wdte.RunFrame(ctx, std.F().WithScope(scope), m)
+			if err, ok := ret.(error); !ok || !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Expected deadline exceeded error, but got %v", ret)
+			}
+		})
+	}
+
+	t.Run("F", func(t *testing.T) {
+		t.Parallel()
+
+		m, err := wdte.Parse(strings.NewReader(`let s => import 'stream'; s.range 3 -> s.repeat -> s.drain;`), std.Import, nil)
+		if err != nil {
+			t.Fatalf("Failed to parse script: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		ret := wdte.Run(ctx, m)
+		if err, ok := ret.(error); !ok || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected deadline exceeded error, but got %v", ret)
+		}
+	})
+}
+
+// TestConcurrency calls shared, already evaluated scripts from many
+// goroutines at once. It's mostly useful when run with the race
+// detector enabled.
+func TestConcurrency(t *testing.T) {
+	const goroutines = 16
+
+	parse := func(t *testing.T, script string) wdte.Func {
+		t.Helper()
+
+		m, err := wdte.Parse(strings.NewReader(script), std.Import, nil)
+		if err != nil {
+			t.Fatalf("Failed to parse script: %v", err)
+		}
+		return m.Call(std.F())
+	}
+
+	parallel := func(t *testing.T, f func(i int)) {
+		t.Helper()
+
+		var wg sync.WaitGroup
+		wg.Add(goroutines)
+		for i := 0; i < goroutines; i++ {
+			go func(i int) {
+				defer wg.Done()
+				f(i)
+			}(i)
+		}
+		wg.Wait()
+	}
+
+	t.Run("Script", func(t *testing.T) {
+		t.Parallel()
+
+		const script = `let s => import 'stream';
+let a => import 'arrays';
+let str => import 'strings';
+s.range 100
+-> s.map (* 3)
+-> s.filter (@ f n => == (% n 2) 0)
+-> s.collect
+-> a.sort >
+-> at 0
+-> str.format '{}'
+;`
+
+		m, err := wdte.Parse(strings.NewReader(script), std.Import, nil)
+		if err != nil {
+			t.Fatalf("Failed to parse script: %v", err)
+		}
+
+		parallel(t, func(int) {
+			ret := m.Call(std.F())
+			if ret != wdte.String("294") {
+				t.Errorf("Expected 294, but got %v", ret)
+			}
+		})
+	})
+
+	t.Run("Memo", func(t *testing.T) {
+		t.Parallel()
+
+		fib := parse(t, `let (memo) fib n => n { <= 1 => n; true => + (fib (- n 2)) (fib (- n 1)); }; fib;`)
+		parallel(t, func(i int) {
+			ret := fib.Call(std.F(), wdte.Number(30+i%5))
+			expected := []wdte.Number{832040, 1346269, 2178309, 3524578, 5702887}[i%5]
+			if ret != expected {
+				t.Errorf("fib %v: Expected %v, but got %v", 30+i%5, expected, ret)
+			}
+		})
+	})
+
+	t.Run("Rand", func(t *testing.T) {
+		t.Parallel()
+
+		next := parse(t, `let rand => import 'rand'; let src => rand.gen 1; let next n => rand.next src; next;`)
+		parallel(t, func(i int) {
+			for j := 0; j < 100; j++ {
+				ret, ok := next.Call(std.F(), wdte.Number(j)).(wdte.Number)
+				if !ok || (ret < 0) || (ret >= 1) {
+					t.Errorf("Unexpected random number: %v", ret)
+				}
+			}
+		})
+	})
+
+	t.Run("Register", func(t *testing.T) {
+		t.Parallel()
+
+		parallel(t, func(i int) {
+			name := fmt.Sprintf("concurrency-test-%v", i)
+			std.Register(name, wdte.S().Add("n", wdte.Number(i)))
+
+			scope, err := std.Import(name)
+			if err != nil {
+				t.Errorf("Failed to import %q: %v", name, err)
+				return
+			}
+			if n := scope.Get("n"); n != wdte.Number(i) {
+				t.Errorf("Expected %v, but got %v", i, n)
+			}
+
+			if _, err := std.Import("stream"); err != nil {
+				t.Errorf("Failed to import stream: %v", err)
+			}
+		})
+	})
+}
+
 type pieceReader struct {
 	pieces []io.Reader
 	i      int
@@ -0,0 +1,331 @@
+package wdteutil
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/DeedleFake/wdte"
+)
+
+// A DecodeError is returned by Decode when a value can't be decoded.
+type DecodeError struct {
+	// Path is the location of the value that couldn't be decoded, such
+	// as servers[2].port. It is empty if the problem was with the
+	// top-level value.
+	Path string
+
+	// Expected is the name of the WDTE type that was expected, such as
+	// Number. It is empty if the error wasn't caused by a type
+	// mismatch.
+	Expected string
+
+	// Got is the name of the type of the value that was found instead.
+	Got string
+
+	// Err is the underlying error, if any, such as an error returned by
+	// the script.
+	Err error
+}
+
+func (err *DecodeError) Error() string {
+	msg := fmt.Sprintf("expected %v, but got %v", err.Expected, err.Got)
+	if err.Err != nil {
+		msg = err.Err.Error()
+	}
+
+	if err.Path == "" {
+		return msg
+	}
+	return fmt.Sprintf("%v: %v", err.Path, msg)
+}
+
+func (err *DecodeError) Unwrap() error {
+	return err.Err
+}
+
+// Decode evaluates f with frame and stores the result in the Go value
+// pointed to by out. It is intended for using WDTE scripts as
+// configuration files, and supports the following types:
+//
+//    * Bools, from wdte.Bool.
+//    * Strings, from wdte.String.
+//    * Numeric types, including *big.Int and *big.Rat, from any of
+//      WDTE's numeric types, as in Func. Unlike with Func, a value
+//      that doesn't fit in the type, or that isn't an integer when
+//      the type is an integer type, is an error.
+//    * Slices and arrays, from wdte.Array. The length of an array must
+//      match.
+//    * Structs, from *wdte.Scope, using the field names described in
+//      Func. Fields that aren't present in the scope are left alone,
+//      as are variables in the scope that don't correspond to fields.
+//    * Maps with string keys, from *wdte.Scope.
+//    * Pointers, which are allocated if they are nil.
+//    * Functions, using FromFunc.
+//    * wdte.Func and the types that implement it, which are stored
+//      directly. Values of Go types exposed with Object are unwrapped.
+//    * Empty interfaces, which are given a bool, string, float64,
+//      int64, []interface{}, or map[string]interface{}, depending on
+//      the value, or the value itself if it is of another type.
+//
+// If a value can't be decoded, a *DecodeError is returned describing
+// where the problem occurred, such as
+//
+//    servers[2].port: expected Number, but got String
+func Decode(frame wdte.Frame, f wdte.Func, out interface{}) error {
+	v := reflect.ValueOf(out)
+	if (v.Kind() != reflect.Ptr) || v.IsNil() {
+		return fmt.Errorf("can't decode into %T: not a non-nil pointer", out)
+	}
+
+	return decode(frame, f.Call(frame), v.Elem(), "")
+}
+
+func decode(frame wdte.Frame, w wdte.Func, v reflect.Value, path string) (err error) {
+	if werr, ok := w.(error); ok {
+		return &DecodeError{Path: path, Err: werr}
+	}
+
+	mismatch := func(expected string) error {
+		return &DecodeError{Path: path, Expected: expected, Got: wdteTypeName(w)}
+	}
+
+	t := v.Type()
+	if o, ok := w.(*object); ok && o.v.Type().AssignableTo(t) {
+		v.Set(o.v)
+		return nil
+	}
+	if (t.Kind() != reflect.Interface) || (t.NumMethod() != 0) {
+		if reflect.TypeOf(w).AssignableTo(t) {
+			v.Set(reflect.ValueOf(w))
+			return nil
+		}
+	}
+
+	if t == bigIntType || t == bigRatType {
+		r, ok, err := fromNumeric(w, t)
+		if !ok {
+			return mismatch("Number")
+		}
+		if err != nil {
+			return &DecodeError{Path: path, Err: err}
+		}
+		v.Set(r)
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		b, ok := w.(wdte.Bool)
+		if !ok {
+			return mismatch("Bool")
+		}
+		v.SetBool(bool(b))
+
+	case reflect.String:
+		s, ok := w.(wdte.String)
+		if !ok {
+			return mismatch("String")
+		}
+		v.SetString(string(s))
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32, reflect.Float64:
+		r, ok, err := fromNumeric(w, t)
+		if !ok {
+			return mismatch("Number")
+		}
+		if err != nil {
+			return &DecodeError{Path: path, Err: err}
+		}
+		v.Set(r)
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return decode(frame, w, v.Elem(), path)
+
+	case reflect.Slice:
+		a, ok := w.(wdte.Array)
+		if !ok {
+			return mismatch("Array")
+		}
+
+		s := reflect.MakeSlice(t, len(a), len(a))
+		for i, e := range a {
+			err := decode(frame, callValue(frame, e, t.Elem()), s.Index(i), fmt.Sprintf("%v[%v]", path, i))
+			if err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+
+	case reflect.Array:
+		a, ok := w.(wdte.Array)
+		if !ok {
+			return mismatch("Array")
+		}
+		if len(a) != t.Len() {
+			return &DecodeError{Path: path, Err: fmt.Errorf("expected Array of length %v, but got length %v", t.Len(), len(a))}
+		}
+
+		for i, e := range a {
+			err := decode(frame, callValue(frame, e, t.Elem()), v.Index(i), fmt.Sprintf("%v[%v]", path, i))
+			if err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return &DecodeError{Path: path, Err: fmt.Errorf("can't decode into %v: map keys must be strings", t)}
+		}
+
+		s, ok := w.(*wdte.Scope)
+		if !ok {
+			return mismatch("Scope")
+		}
+
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(t))
+		}
+		for _, id := range s.Known() {
+			e := reflect.New(t.Elem()).Elem()
+			err := decode(frame, callValue(frame, s.Get(id), t.Elem()), e, joinPath(path, string(id)))
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(id).Convert(t.Key()), e)
+		}
+
+	case reflect.Struct:
+		s, ok := w.(*wdte.Scope)
+		if !ok {
+			return mismatch("Scope")
+		}
+
+		for _, f := range fields(t) {
+			fv := s.Get(wdte.ID(f.name))
+			if fv == nil {
+				continue
+			}
+
+			dst := fieldByIndex(v, f.index)
+			err := decode(frame, callValue(frame, fv, dst.Type()), dst, joinPath(path, f.name))
+			if err != nil {
+				return err
+			}
+		}
+
+	case reflect.Func:
+		v.Set(FromFunc(frame, w, t))
+
+	case reflect.Interface:
+		if t.NumMethod() != 0 {
+			return &DecodeError{Path: path, Err: fmt.Errorf("can't decode %v into %v", wdteTypeName(w), t)}
+		}
+		v.Set(reflect.ValueOf(natural(frame, w)))
+
+	default:
+		return &DecodeError{Path: path, Err: fmt.Errorf("can't decode into %v", t)}
+	}
+
+	return nil
+}
+
+// callValue evaluates w, unless the type that it is being decoded into
+// is a function type, in which case w is returned as is.
+func callValue(frame wdte.Frame, w wdte.Func, t reflect.Type) wdte.Func {
+	if (t.Kind() == reflect.Func) || (t == funcType) {
+		return w
+	}
+	return w.Call(frame)
+}
+
+// fieldByIndex is like v.FieldByIndex, but allocates nil embedded
+// struct pointers along the way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		if (v.Kind() == reflect.Ptr) && v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = reflect.Indirect(v).Field(i)
+	}
+	return v
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// natural converts w into the Go type most naturally corresponding to
+// it, for decoding into empty interfaces.
+func natural(frame wdte.Frame, w wdte.Func) interface{} {
+	switch w := w.(type) {
+	case wdte.Bool:
+		return bool(w)
+	case wdte.String:
+		return string(w)
+	case wdte.Number:
+		return float64(w)
+	case wdte.Int:
+		return int64(w)
+	case wdte.Array:
+		a := make([]interface{}, 0, len(w))
+		for _, e := range w {
+			a = append(a, natural(frame, e.Call(frame)))
+		}
+		return a
+	case *wdte.Scope:
+		m := make(map[string]interface{})
+		for _, id := range w.Known() {
+			m[string(id)] = natural(frame, w.Get(id).Call(frame))
+		}
+		return m
+	}
+
+	return w
+}
+
+// wdteTypeName returns the name of the WDTE type of w, such as Number
+// or Scope.
+func wdteTypeName(w wdte.Func) string {
+	switch w.(type) {
+	case wdte.Number, wdte.Int, wdte.BigInt, wdte.BigRat:
+		return "Number"
+	}
+
+	t := reflect.TypeOf(w)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Name() == "" {
+		return t.String()
+	}
+	return t.Name()
+}
+
+// Encode converts the Go value v into a WDTE value the same way that
+// Func converts return values. It is the counterpart of Decode, so, for
+// example, encoding a struct produces a *wdte.Scope that can be
+// decoded back into a struct of the same type.
+func Encode(v interface{}) (w wdte.Func, err error) {
+	if v == nil {
+		return nil, errors.New("can't encode nil")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			if rerr, ok := r.(error); ok {
+				err = rerr
+				return
+			}
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	return toWDTE(reflect.ValueOf(v)), nil
+}
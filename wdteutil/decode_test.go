@@ -0,0 +1,210 @@
+package wdteutil_test
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DeedleFake/wdte"
+	"github.com/DeedleFake/wdte/std"
+	"github.com/DeedleFake/wdte/wdteutil"
+)
+
+type testServer struct {
+	Host string `wdte:"host"`
+	Port int    `wdte:"port"`
+}
+
+type testConfig struct {
+	Name    string                 `wdte:"name"`
+	Debug   bool                   `wdte:"debug"`
+	Servers []testServer           `wdte:"servers"`
+	Primary *testServer            `wdte:"primary"`
+	Limits  map[string]uint16      `wdte:"limits"`
+	Size    *big.Int               `wdte:"size"`
+	Point   [2]float64             `wdte:"point"`
+	Extra   map[string]interface{} `wdte:"extra"`
+	Double  func(int) int          `wdte:"double"`
+	Ignored string                 `wdte:"-"`
+}
+
+func decodeScript(t *testing.T, script string, out interface{}) error {
+	m, err := wdte.Parse(strings.NewReader(script), std.Import, nil)
+	if err != nil {
+		t.Fatalf("Failed to parse script: %v", err)
+	}
+	return wdteutil.Decode(std.F(), m, out)
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("Config", func(t *testing.T) {
+		const script = `
+let double n => * n 2;
+(|
+	let name => 'test';
+	let debug => true;
+	let servers => [
+		(| let host => 'a'; let port => 80 |);
+		(| let host => 'b'; let port => 8080 |);
+	];
+	let primary => (| let host => 'c'; let port => 1 |);
+	let limits => (| let conns => 10; let reqs => 20 |);
+	let size => 123456789;
+	let point => [1.5; 2.5];
+	let extra => (| let list => ['x'; 3]; let flag => false |);
+	let double => double;
+	let Ignored => 'no';
+|);
+`
+
+		c := testConfig{Ignored: "yes"}
+		err := decodeScript(t, script, &c)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		size, _ := new(big.Int).SetString("123456789", 10)
+		expected := testConfig{
+			Name:    "test",
+			Debug:   true,
+			Servers: []testServer{{"a", 80}, {"b", 8080}},
+			Primary: &testServer{"c", 1},
+			Limits:  map[string]uint16{"conns": 10, "reqs": 20},
+			Size:    size,
+			Point:   [2]float64{1.5, 2.5},
+			Extra: map[string]interface{}{
+				"list": []interface{}{"x", float64(3)},
+				"flag": false,
+			},
+			Ignored: "yes",
+		}
+
+		if c.Double == nil {
+			t.Fatal("double was not decoded")
+		}
+		if r := c.Double(21); r != 42 {
+			t.Errorf("double 21 returned %v", r)
+		}
+		c.Double = nil
+
+		if !reflect.DeepEqual(c, expected) {
+			t.Errorf("Decoded\n\t%#v\nExpected\n\t%#v", c, expected)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			script string
+			err    string
+		}{
+			{
+				name:   "Path",
+				script: `(| let servers => [(| let port => 1 |); (| let port => 2 |); (| let port => 'x' |)] |);`,
+				err:    "servers[2].port: expected Number, but got String",
+			},
+			{
+				name:   "Top",
+				script: `3;`,
+				err:    "expected Scope, but got Number",
+			},
+			{
+				name:   "ArrayLength",
+				script: `(| let point => [1; 2; 3] |);`,
+				err:    "point: expected Array of length 2, but got length 3",
+			},
+			{
+				name:   "Script",
+				script: `(| let name => nope |);`,
+				err:    `"nope" is not in scope`,
+			},
+			{
+				name:   "Overflow",
+				script: `(| let limits => (| let conns => 70000 |) |);`,
+				err:    "limits.conns: 70000 overflows uint16",
+			},
+			{
+				name:   "Negative",
+				script: `(| let limits => (| let conns => -1 |) |);`,
+				err:    "limits.conns: -1 overflows uint16",
+			},
+			{
+				name:   "BigOverflow",
+				script: `(| let primary => (| let port => 100000000000000000000000n |) |);`,
+				err:    "primary.port: 100000000000000000000000 overflows int",
+			},
+			{
+				name:   "NotInteger",
+				script: `(| let primary => (| let port => 80.5 |) |);`,
+				err:    "primary.port: 80.5 is not an integer",
+			},
+			{
+				name:   "BigIntNotInteger",
+				script: `(| let size => 1.5r |);`,
+				err:    "size: 3/2 is not an integer",
+			},
+			{
+				name:   "FloatOverflow",
+				script: `(| let point => [1; 1` + strings.Repeat("0", 400) + `n] |);`,
+				err:    "point[1]: 1" + strings.Repeat("0", 400) + " overflows float64",
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				var c testConfig
+				err := decodeScript(t, test.script, &c)
+
+				var derr *wdteutil.DecodeError
+				if !errors.As(err, &derr) {
+					t.Fatalf("Expected *DecodeError, but got %#v", err)
+				}
+				if !strings.HasPrefix(err.Error(), test.err) {
+					t.Errorf("Expected error %q, but got %q", test.err, err)
+				}
+			})
+		}
+	})
+
+	t.Run("NotPointer", func(t *testing.T) {
+		var c testConfig
+		if err := wdteutil.Decode(std.F(), wdte.Number(3), c); err == nil {
+			t.Error("Decoding into a non-pointer succeeded")
+		}
+	})
+}
+
+func TestEncode(t *testing.T) {
+	in := testConfig{
+		Name:    "test",
+		Servers: []testServer{{"a", 80}},
+		Limits:  map[string]uint16{"conns": 10},
+		Point:   [2]float64{1, 2},
+		Double:  func(n int) int { return n * 2 },
+	}
+
+	w, err := wdteutil.Encode(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out testConfig
+	if err := wdteutil.Decode(std.F(), w, &out); err != nil {
+		t.Fatal(err)
+	}
+	if r := out.Double(3); r != 6 {
+		t.Errorf("double 3 returned %v", r)
+	}
+	in.Double, out.Double = nil, nil
+	out.Extra = nil
+
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("Round trip produced\n\t%#v\nExpected\n\t%#v", out, in)
+	}
+
+	if _, err := wdteutil.Encode(nil); err == nil {
+		t.Error("Encoding nil succeeded")
+	}
+}
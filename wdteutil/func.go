@@ -14,8 +14,21 @@ import (
 // values. The given name is used to name the frame used inside the
 // function.
 //
-// Note that currently this is limited to functions that have a single
-// return value.
+// f may return a single value, no values, an error, or a single value
+// followed by an error. If f returns a non-nil error, the returned
+// function returns it wrapped in a wdte.Error. If f returns no values
+// other than a nil error, the returned function returns its first
+// argument, or itself if it had no arguments, allowing it to be used
+// in a chain.
+//
+// If the first parameter of f is a context.Context or a wdte.Frame, it
+// is passed the context of the frame that the returned function is
+// called with or the frame itself, respectively, rather than an
+// argument. If f is variadic, any arguments past the ones required
+// are converted to the type of the variadic parameter and passed to
+// it. If the returned function is called with fewer arguments than
+// are required, it returns a function that saves the arguments given
+// and waits for the rest.
 //
 // Unrecognized types are passed through with an attempted conversion,
 // allowing a function to, for example, take a stream.Stream as an
@@ -28,12 +41,39 @@ import (
 //    * Arrays and slices. Note that the passed WDTE array's length
 //      must match the expected length of the array in the Go
 //      function's arguments.
+//    * Numbers. Any of WDTE's numeric types may be passed for any of
+//      Go's integer and floating point types, as well as *big.Int and
+//      *big.Rat. Conversions are exact wherever the types allow. A
+//      number that doesn't fit in the parameter's type, such as a
+//      negative number for an unsigned type or a fraction for an
+//      integer type, results in a wdte.Error being returned.
+//    * Structs and pointers to structs, from a *wdte.Scope. Each
+//      field is set from the variable in the scope with the field's
+//      name, as described below. Fields without a corresponding
+//      variable are left as their zero values.
+//    * Maps with string keys, from a *wdte.Scope. The map contains
+//      every variable known to the scope.
 //
 // Return types:
+//    * int64 and uint64, which are converted to wdte.Int so that no
+//      precision is lost. A uint64 that doesn't fit into an int64 is
+//      converted to a wdte.BigInt. Other integer types, as well as
+//      floating point types, are converted to wdte.Number.
+//    * *big.Int and *big.Rat, which are converted to wdte.BigInt and
+//      wdte.BigRat, respectively.
 //    * Arrays and slices.
-//    * Pointers.
+//    * Structs, which are converted to a *wdte.Scope containing their
+//      exported fields. A field's name in the scope can be changed
+//      with a struct tag, such as `wdte:"name"`, and a field with the
+//      tag `wdte:"-"` is skipped. The fields of embedded structs are
+//      included as though they were fields of the outer struct.
+//    * Maps with string keys, which are converted to a *wdte.Scope.
+//    * Pointers. Pointers to types that have methods, as well as nil
+//      pointers, are wrapped with Object. Other pointers are
+//      dereferenced and their targets converted.
 //    * Functions that are supported by this function. The functions
 //      will use a frame with the name "<auto>".
+//    * Values of any other type are wrapped with Object.
 func Func(name string, f interface{}) wdte.Func {
 	v := reflect.ValueOf(f)
 
@@ -41,7 +81,27 @@ func Func(name string, f interface{}) wdte.Func {
 	if t.Kind() != reflect.Func {
 		panic(errors.New("f is not a function"))
 	}
-	if t.NumOut() != 1 {
+
+	var inject reflect.Type
+	if (t.NumIn() > 0) && ((t.In(0) == contextType) || (t.In(0) == frameType)) {
+		inject = t.In(0)
+	}
+
+	params := t.NumIn()
+	if inject != nil {
+		params--
+	}
+	required := params
+	if t.IsVariadic() {
+		required--
+	}
+
+	results := t.NumOut()
+	hasErr := (results > 0) && (t.Out(results-1) == errorType)
+	if hasErr {
+		results--
+	}
+	if results > 1 {
 		panic(fmt.Errorf("invalid number of returns: %v", t.NumOut()))
 	}
 
@@ -49,17 +109,41 @@ func Func(name string, f interface{}) wdte.Func {
 	r = wdte.GoFunc(func(frame wdte.Frame, args ...wdte.Func) wdte.Func {
 		frame = frame.Sub(wdte.ID(name))
 
-		if len(args) < t.NumIn() {
+		if len(args) < required {
 			return SaveArgs(r, args...)
 		}
 
-		in := make([]reflect.Value, t.NumIn())
-		for i := range in {
-			in[i] = fromWDTE(frame, args[i].Call(frame), t.In(i))
+		in := make([]reflect.Value, 0, t.NumIn()+len(args)-params)
+		switch inject {
+		case contextType:
+			in = append(in, reflect.ValueOf(frame.Context()))
+		case frameType:
+			in = append(in, reflect.ValueOf(frame))
+		}
+		for i := 0; i < required; i++ {
+			in = append(in, fromWDTE(frame, args[i].Call(frame), t.In(len(in))))
+		}
+		if t.IsVariadic() {
+			elem := t.In(t.NumIn() - 1).Elem()
+			for _, arg := range args[required:] {
+				in = append(in, fromWDTE(frame, arg.Call(frame), elem))
+			}
 		}
 
 		out := v.Call(in)
 
+		if hasErr {
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				return &wdte.Error{Err: err, Frame: frame}
+			}
+		}
+
+		if results == 0 {
+			if len(args) == 0 {
+				return r
+			}
+			return args[0]
+		}
 		return toWDTE(out[0])
 	})
 	return r
@@ -69,27 +153,60 @@ func Func(name string, f interface{}) wdte.Func {
 // the signature given by expected that, when called, calls w with
 // frame. Type conversions are handled the same as in Func, but in
 // reverse, such that the return type stipulations in Func apply to
-// the arguments to w, and vice versa for the return value of w. The
-// requested function type must return exactly zero or one values.
+// the arguments to w, and vice versa for the return value of w. If
+// expected is variadic, the elements of its variadic parameter are
+// passed to w as separate arguments.
+//
+// The requested function type may return zero values, one value, an
+// error, or one value followed by an error. If it returns an error and
+// w returns a WDTE error, that error is returned from the Go function
+// along with the zero value of the other return, if any.
 func FromFunc(frame wdte.Frame, w wdte.Func, expected reflect.Type) reflect.Value {
 	if expected.Kind() != reflect.Func {
 		panic(errors.New("expected is not a function type"))
 	}
-	if expected.NumOut() > 1 {
+
+	results := expected.NumOut()
+	hasErr := (results > 0) && (expected.Out(results-1) == errorType)
+	if hasErr {
+		results--
+	}
+	if results > 1 {
 		panic(fmt.Errorf("invalid number of returns: %v", expected.NumOut()))
 	}
 
 	return reflect.MakeFunc(expected, func(args []reflect.Value) []reflect.Value {
+		if expected.IsVariadic() {
+			last := args[len(args)-1]
+			args = args[:len(args)-1]
+			for i := 0; i < last.Len(); i++ {
+				args = append(args, last.Index(i))
+			}
+		}
+
 		wargs := make([]wdte.Func, 0, len(args))
 		for _, arg := range args {
 			wargs = append(wargs, toWDTE(arg))
 		}
 
 		r := w.Call(frame, wargs...)
-		if expected.NumOut() == 0 {
-			// TODO: Should zero return values be allowed?
-			return nil
+
+		out := make([]reflect.Value, 0, expected.NumOut())
+		if hasErr {
+			if err, ok := r.(error); ok {
+				if results == 1 {
+					out = append(out, reflect.Zero(expected.Out(0)))
+				}
+				return append(out, reflect.ValueOf(&err).Elem())
+			}
+		}
+
+		if results == 1 {
+			out = append(out, fromWDTE(frame, r, expected.Out(0)))
+		}
+		if hasErr {
+			out = append(out, reflect.Zero(errorType))
 		}
-		return []reflect.Value{fromWDTE(frame, r, expected.Out(0))}
+		return out
 	})
 }
@@ -1,7 +1,10 @@
 package wdteutil_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math/big"
 	"reflect"
 	"strings"
 	"testing"
@@ -26,6 +29,7 @@ func TestFunc(t *testing.T) {
 		args  []wdte.Func
 		ret   wdte.Func
 		calls []wdte.Func
+		err   string
 	}{
 		{
 			name: "Number",
@@ -35,6 +39,54 @@ func TestFunc(t *testing.T) {
 			args: []wdte.Func{wdte.Number(2)},
 			ret:  wdte.Number(5),
 		},
+		{
+			name: "Int64",
+			f: func(v int64) int64 {
+				return v + 1
+			},
+			args: []wdte.Func{wdte.Int(1<<53 + 1)},
+			ret:  wdte.Int(1<<53 + 2),
+		},
+		{
+			name: "Uint64",
+			f: func(v uint64) uint64 {
+				return v * 2
+			},
+			args: []wdte.Func{wdte.Int(1<<62 + 1)},
+			ret:  wdte.BigInt{Int: new(big.Int).SetUint64(1<<63 + 2)},
+		},
+		{
+			name: "Overflow",
+			f: func(v int8) int8 {
+				return v
+			},
+			args: []wdte.Func{wdte.Number(300)},
+			err:  "300 overflows int8",
+		},
+		{
+			name: "NegativeUnsigned",
+			f: func(v uint) uint {
+				return v
+			},
+			args: []wdte.Func{wdte.Int(-1)},
+			err:  "-1 overflows uint",
+		},
+		{
+			name: "NotInteger",
+			f: func(v int) int {
+				return v
+			},
+			args: []wdte.Func{wdte.Number(1.5)},
+			err:  "1.5 is not an integer",
+		},
+		{
+			name: "BigInt",
+			f: func(v *big.Int) *big.Int {
+				return new(big.Int).Neg(v)
+			},
+			args: []wdte.Func{wdte.Int(3)},
+			ret:  wdte.BigInt{Int: big.NewInt(-3)},
+		},
 		{
 			name: "Array",
 			f: func(a [3]int) [2]int {
@@ -103,6 +155,14 @@ func TestFunc(t *testing.T) {
 			f := wdteutil.Func(test.name, test.f)
 			r := f.Call(wdte.F(), test.args...)
 
+			if test.err != "" {
+				err, ok := r.(wdte.Error)
+				if !ok || (err.Err.Error() != test.err) {
+					t.Errorf("Expected error %q, but got %#v", test.err, r)
+				}
+				return
+			}
+
 			if (test.ret != nil) && !reflect.DeepEqual(r, test.ret) {
 				t.Errorf("Got %#v", r)
 				t.Errorf("Expected %#v", test.ret)
@@ -154,3 +214,262 @@ func ExampleFunc() {
 
 	// Output: 5
 }
+
+type testEmbedded struct {
+	Port int `wdte:"port"`
+}
+
+type testRecord struct {
+	testEmbedded
+
+	Name    string `wdte:"name"`
+	Tags    []string
+	Ignored string `wdte:"-"`
+	hidden  int
+}
+
+func TestFuncRecords(t *testing.T) {
+	record := wdte.S().Map(map[wdte.ID]wdte.Func{
+		"name": wdte.String("server"),
+		"port": wdte.Number(80),
+		"Tags": wdte.Array{wdte.String("a"), wdte.String("b")},
+	})
+
+	tests := []struct {
+		name string
+		f    interface{}
+		args []wdte.Func
+		ret  wdte.Func
+	}{
+		{
+			name: "StructArg",
+			f: func(r testRecord) string {
+				return fmt.Sprintf("%v:%v %v", r.Name, r.Port, r.Tags)
+			},
+			args: []wdte.Func{record},
+			ret:  wdte.String("server:80 [a b]"),
+		},
+		{
+			name: "StructPointerArg",
+			f: func(r *testRecord) int {
+				return r.Port
+			},
+			args: []wdte.Func{record},
+			ret:  wdte.Number(80),
+		},
+		{
+			name: "StructReturn",
+			f: func(name string) testRecord {
+				return testRecord{
+					testEmbedded: testEmbedded{Port: 8080},
+					Name:         name,
+					Tags:         []string{"x"},
+					Ignored:      "ignored",
+					hidden:       3,
+				}
+			},
+			args: []wdte.Func{wdte.String("web")},
+			ret: wdte.S().Map(map[wdte.ID]wdte.Func{
+				"name": wdte.String("web"),
+				"port": wdte.Number(8080),
+				"Tags": wdte.Array{wdte.String("x")},
+			}),
+		},
+		{
+			name: "MapArg",
+			f: func(m map[string]int) int {
+				return m["a"] + m["b"]
+			},
+			args: []wdte.Func{wdte.S().Map(map[wdte.ID]wdte.Func{
+				"a": wdte.Number(1),
+				"b": wdte.Number(2),
+			})},
+			ret: wdte.Number(3),
+		},
+		{
+			name: "MapReturn",
+			f: func(v int) map[string]int {
+				return map[string]int{"v": v, "double": v * 2}
+			},
+			args: []wdte.Func{wdte.Number(2)},
+			ret: wdte.S().Map(map[wdte.ID]wdte.Func{
+				"v":      wdte.Number(2),
+				"double": wdte.Number(4),
+			}),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := wdteutil.Func(test.name, test.f).Call(wdte.F(), test.args...)
+			if !wdte.Equal(r, test.ret) {
+				t.Errorf("Got %v", r)
+				t.Errorf("Expected %v", test.ret)
+			}
+		})
+	}
+
+	t.Run("NotScope", func(t *testing.T) {
+		r := wdteutil.Func("test", func(r testRecord) int { return 0 }).Call(wdte.F(), wdte.Number(3))
+		if _, ok := r.(error); !ok {
+			t.Errorf("Expected an error, but got %v", r)
+		}
+	})
+
+	t.Run("FromFunc", func(t *testing.T) {
+		w := wdte.GoFunc(func(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+			return args[0]
+		})
+
+		var f func(testRecord) map[string]interface{}
+		reflect.ValueOf(&f).Elem().Set(wdteutil.FromFunc(wdte.F(), w, reflect.TypeOf(f)))
+
+		m := f(testRecord{Name: "round"})
+		if m["name"] != wdte.String("round") {
+			t.Errorf("Expected name to be round, but got %v", m["name"])
+		}
+	})
+}
+
+func TestFuncSignatures(t *testing.T) {
+	errTest := errors.New("test error")
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+	frame := wdte.F().WithContext(ctx)
+
+	tests := []struct {
+		name string
+		f    interface{}
+		args []wdte.Func
+		ret  wdte.Func
+		err  error
+	}{
+		{
+			name: "ValueError",
+			f: func(v int) (int, error) {
+				return v * 2, nil
+			},
+			args: []wdte.Func{wdte.Number(2)},
+			ret:  wdte.Number(4),
+		},
+		{
+			name: "ValueError/Error",
+			f: func(v int) (int, error) {
+				return 0, errTest
+			},
+			args: []wdte.Func{wdte.Number(2)},
+			err:  errTest,
+		},
+		{
+			name: "Error",
+			f: func(v int) error {
+				return nil
+			},
+			args: []wdte.Func{wdte.Number(2)},
+			ret:  wdte.Number(2),
+		},
+		{
+			name: "Error/Error",
+			f: func(v int) error {
+				return errTest
+			},
+			args: []wdte.Func{wdte.Number(2)},
+			err:  errTest,
+		},
+		{
+			name: "None",
+			f:    func(s string) {},
+			args: []wdte.Func{wdte.String("chained")},
+			ret:  wdte.String("chained"),
+		},
+		{
+			name: "Variadic",
+			f: func(sep string, parts ...string) string {
+				return strings.Join(parts, sep)
+			},
+			args: []wdte.Func{wdte.String(","), wdte.String("a"), wdte.String("b"), wdte.String("c")},
+			ret:  wdte.String("a,b,c"),
+		},
+		{
+			name: "Variadic/Empty",
+			f: func(sep string, parts ...string) string {
+				return strings.Join(parts, sep)
+			},
+			args: []wdte.Func{wdte.String(",")},
+			ret:  wdte.String(""),
+		},
+		{
+			name: "Context",
+			f: func(ctx context.Context, v int) string {
+				return fmt.Sprint(ctx.Value(key{}), v)
+			},
+			args: []wdte.Func{wdte.Number(3)},
+			ret:  wdte.String("value3"),
+		},
+		{
+			name: "Frame",
+			f: func(frame wdte.Frame, v int) string {
+				return fmt.Sprint(frame.ID(), v)
+			},
+			args: []wdte.Func{wdte.Number(3)},
+			ret:  wdte.String("Frame3"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := wdteutil.Func(test.name, test.f).Call(frame, test.args...)
+
+			if test.err != nil {
+				err, ok := r.(error)
+				if !ok || !errors.Is(err, test.err) {
+					t.Errorf("Expected error %v, but got %v", test.err, r)
+				}
+				return
+			}
+
+			if !wdte.Equal(r, test.ret) {
+				t.Errorf("Got %#v", r)
+				t.Errorf("Expected %#v", test.ret)
+			}
+		})
+	}
+
+	t.Run("Partial", func(t *testing.T) {
+		f := wdteutil.Func("test", func(ctx context.Context, a, b int, rest ...int) int {
+			sum := a + b
+			for _, v := range rest {
+				sum += v
+			}
+			return sum
+		})
+
+		r := f.Call(frame, wdte.Number(1)).Call(frame, wdte.Number(2))
+		if !wdte.Equal(r, wdte.Number(3)) {
+			t.Errorf("Expected 3, but got %v", r)
+		}
+	})
+
+	t.Run("FromFunc", func(t *testing.T) {
+		w := wdte.GoFunc(func(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+			if len(args) == 0 {
+				return &wdte.Error{Err: errTest, Frame: frame}
+			}
+			return wdte.Number(len(args))
+		})
+
+		var f func(...int) (int, error)
+		reflect.ValueOf(&f).Elem().Set(wdteutil.FromFunc(wdte.F(), w, reflect.TypeOf(f)))
+
+		n, err := f(1, 2, 3)
+		if (n != 3) || (err != nil) {
+			t.Errorf("Expected (3, nil), but got (%v, %v)", n, err)
+		}
+
+		_, err = f()
+		if !errors.Is(err, errTest) {
+			t.Errorf("Expected %v, but got %v", errTest, err)
+		}
+	})
+}
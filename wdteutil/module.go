@@ -0,0 +1,244 @@
+package wdteutil
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/DeedleFake/wdte"
+)
+
+// Module is a builder for modules made from Go values. It provides an
+// alternative to building a module's scope by hand. For example,
+//
+//    var Module = wdteutil.NewModule("geo").
+//      Func("distance", Distance, "distance returns the distance between two points.").
+//      Value("origin", Origin, "origin is the point (0, 0).").
+//      Sub("units", units.Module)
+//
+//    func init() {
+//      Module.Register(std.Register)
+//    }
+//
+// A Module should not be modified after Scope or Register have been
+// called.
+type Module struct {
+	name    string
+	doc     string
+	members map[wdte.ID]*member
+}
+
+type member struct {
+	f   wdte.Func
+	sub *Module
+	doc Doc
+}
+
+// Doc describes a member of a module.
+type Doc struct {
+	// Name is the ID of the member in the module.
+	Name wdte.ID
+
+	// Kind is one of "func", "value", or "module".
+	Kind string
+
+	// Signature is a description of the member's arguments and return
+	// value, generated from its Go type. It is empty for members that
+	// aren't functions wrapped with Func.
+	Signature string
+
+	// Text is the documentation given when the member was added.
+	Text string
+}
+
+// NewModule returns a new, empty module with the given name.
+func NewModule(name string) *Module {
+	return &Module{
+		name:    name,
+		members: make(map[wdte.ID]*member),
+	}
+}
+
+// Name returns the name of the module.
+func (m *Module) Name() string {
+	return m.name
+}
+
+// Doc sets the documentation of the module itself.
+func (m *Module) Doc(text string) *Module {
+	m.doc = text
+	return m
+}
+
+// Func adds a function to the module. If f is a wdte.Func, it is
+// added as is. Otherwise, it is wrapped using Func.
+func (m *Module) Func(id wdte.ID, f interface{}, doc string) *Module {
+	var w wdte.Func
+	var sig string
+	switch f := f.(type) {
+	case wdte.Func:
+		w = f
+	case func(wdte.Frame, ...wdte.Func) wdte.Func:
+		w = wdte.GoFunc(f)
+	default:
+		w = Func(string(id), f)
+		sig = signature(id, reflect.TypeOf(f))
+	}
+
+	m.members[id] = &member{
+		f:   w,
+		doc: Doc{Name: id, Kind: "func", Signature: sig, Text: doc},
+	}
+	return m
+}
+
+// Value adds a value to the module. v is converted the same way as
+// the return values of functions wrapped with Func.
+func (m *Module) Value(id wdte.ID, v interface{}, doc string) *Module {
+	m.members[id] = &member{
+		f:   toWDTE(reflect.ValueOf(v)),
+		doc: Doc{Name: id, Kind: "value", Text: doc},
+	}
+	return m
+}
+
+// Sub adds sub to the module as a nested module, allowing its members
+// to be accessed with the sub syntax, such as geo.units.meter.
+func (m *Module) Sub(id wdte.ID, sub *Module) *Module {
+	m.members[id] = &member{
+		sub: sub,
+		doc: Doc{Name: id, Kind: "module", Text: sub.doc},
+	}
+	return m
+}
+
+// Scope returns a new scope containing the members of the module. The
+// scope's metadata, as returned by its Module method, contains the
+// module's name.
+func (m *Module) Scope() *wdte.Scope {
+	vars := make(map[wdte.ID]wdte.Func, len(m.members))
+	for id, member := range m.members {
+		if member.sub != nil {
+			vars[id] = member.sub.Scope()
+			continue
+		}
+		vars[id] = member.f
+	}
+
+	return wdte.S().Map(vars).WithModule(&wdte.ModuleInfo{Name: m.name})
+}
+
+// Register registers the module under its name using register, which
+// is usually std.Register. Submodules are also registered separately,
+// under the name of the module followed by a slash and their ID. For
+// example, a submodule added with the ID "units" to a module named
+// "geo" is registered as "geo/units".
+func (m *Module) Register(register func(name string, module *wdte.Scope)) {
+	m.register(m.name, register)
+}
+
+func (m *Module) register(name string, register func(string, *wdte.Scope)) {
+	register(name, m.Scope())
+	for id, member := range m.members {
+		if member.sub != nil {
+			member.sub.register(name+"/"+string(id), register)
+		}
+	}
+}
+
+// Docs returns the documentation of the members of the module, sorted
+// by ID.
+func (m *Module) Docs() []Doc {
+	docs := make([]Doc, 0, len(m.members))
+	for _, member := range m.members {
+		docs = append(docs, member.doc)
+	}
+
+	sort.Slice(docs, func(i1, i2 int) bool {
+		return docs[i1].Name < docs[i2].Name
+	})
+	return docs
+}
+
+// String returns the documentation of the module formatted as text.
+func (m *Module) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "module %v\n", m.name)
+	if m.doc != "" {
+		fmt.Fprintf(&buf, "\n%v\n", m.doc)
+	}
+
+	for _, doc := range m.Docs() {
+		buf.WriteByte('\n')
+		switch {
+		case doc.Signature != "":
+			fmt.Fprintf(&buf, "%v\n", doc.Signature)
+		default:
+			fmt.Fprintf(&buf, "%v (%v)\n", doc.Name, doc.Kind)
+		}
+		if doc.Text != "" {
+			fmt.Fprintf(&buf, "    %v\n", doc.Text)
+		}
+	}
+
+	return buf.String()
+}
+
+// signature generates a WDTE-style signature, such as
+//
+//    add Number Number -> Number
+//
+// for the Go function type t, taking into account the handling of
+// arguments and return values done by Func.
+func signature(id wdte.ID, t reflect.Type) string {
+	parts := []string{string(id)}
+
+	start := 0
+	if (t.NumIn() > 0) && ((t.In(0) == contextType) || (t.In(0) == frameType)) {
+		start = 1
+	}
+	for i := start; i < t.NumIn(); i++ {
+		if t.IsVariadic() && (i == t.NumIn()-1) {
+			parts = append(parts, typeName(t.In(i).Elem())+"...")
+			continue
+		}
+		parts = append(parts, typeName(t.In(i)))
+	}
+
+	if (t.NumOut() > 0) && (t.Out(0) != errorType) {
+		parts = append(parts, "->", typeName(t.Out(0)))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// typeName returns the name of the WDTE type that values of the Go
+// type t are converted to or from.
+func typeName(t reflect.Type) string {
+	switch t {
+	case bigIntType:
+		return "BigInt"
+	case bigRatType:
+		return "BigRat"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "Bool"
+	case reflect.Int64, reflect.Uint64:
+		return "Int"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uintptr, reflect.Float32, reflect.Float64:
+		return "Number"
+	case reflect.String:
+		return "String"
+	case reflect.Array, reflect.Slice:
+		return "Array"
+	case reflect.Map, reflect.Struct:
+		return "Scope"
+	case reflect.Func:
+		return "Func"
+	}
+
+	return t.String()
+}
@@ -0,0 +1,74 @@
+package wdteutil_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DeedleFake/wdte"
+	"github.com/DeedleFake/wdte/importer"
+	"github.com/DeedleFake/wdte/wdteutil"
+)
+
+func TestModule(t *testing.T) {
+	units := wdteutil.NewModule("units").
+		Doc("units contains unit conversions.").
+		Value("meter", 1, "meter is one meter.")
+
+	m := wdteutil.NewModule("geo").
+		Func("add", func(a, b float64) float64 { return a + b }, "add adds two numbers.").
+		Func("join", func(ctx context.Context, sep string, parts ...string) (string, error) {
+			return strings.Join(parts, sep), nil
+		}, "").
+		Func("raw", testFunc, "raw adds one to its argument.").
+		Value("origin", [2]int{0, 0}, "origin is the origin.").
+		Sub("units", units)
+
+	registered := make(importer.Modules)
+	m.Register(func(name string, s *wdte.Scope) {
+		registered[name] = s
+	})
+
+	if s := registered["geo"]; (s == nil) || (s.Module() == nil) || (s.Module().Name != "geo") {
+		t.Fatalf("Module was not registered with metadata: %v", registered)
+	}
+	if registered["geo/units"] == nil {
+		t.Fatalf("Submodule was not registered: %v", registered)
+	}
+
+	const script = `let geo => import 'geo'; [geo.add 1 2; geo.join ',' 'a' 'b'; geo.raw 2; geo.origin; geo.units.meter];`
+	c, err := wdte.Parse(strings.NewReader(script), registered, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ret := c.Call(wdte.F())
+	expected := wdte.Array{
+		wdte.Number(3),
+		wdte.String("a,b"),
+		wdte.Number(3),
+		wdte.Array{wdte.Number(0), wdte.Number(0)},
+		wdte.Number(1),
+	}
+	if !wdte.Equal(ret, expected) {
+		t.Errorf("Expected %v, but got %v", expected, ret)
+	}
+
+	docs := m.Docs()
+	signatures := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		signatures = append(signatures, fmt.Sprintf("%v %v %q", doc.Kind, doc.Signature, doc.Text))
+	}
+	expectedDocs := []string{
+		`func add Number Number -> Number "add adds two numbers."`,
+		`func join String String... -> String ""`,
+		`value  "origin is the origin."`,
+		`func  "raw adds one to its argument."`,
+		`module  "units contains unit conversions."`,
+	}
+	if !reflect.DeepEqual(signatures, expectedDocs) {
+		t.Errorf("Expected docs\n\t%v\nbut got\n\t%v", strings.Join(expectedDocs, "\n\t"), strings.Join(signatures, "\n\t"))
+	}
+}
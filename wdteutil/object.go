@@ -0,0 +1,96 @@
+package wdteutil
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/DeedleFake/wdte"
+)
+
+// Object returns a wdte.Func that exposes the exported methods and
+// fields of the Go value v to WDTE scripts. Members can be accessed
+// either with the sub syntax, such as
+//
+//    client.Query 'select 1'
+//
+// or with at, such as at client 'Query'. Methods are wrapped using
+// Func, and fields are converted the same way as Func converts return
+// values. Fields are named as described in Func. Methods that take no
+// arguments are called as soon as they are accessed, so they behave
+// like fields.
+//
+// When an object is passed to a Go function wrapped with Func that
+// expects a type that v is assignable to, v itself is passed, so
+// objects can be passed back into Go unchanged.
+func Object(v interface{}) wdte.Func {
+	return &object{v: reflect.ValueOf(v)}
+}
+
+type object struct {
+	v reflect.Value
+}
+
+func (o *object) Call(frame wdte.Frame, args ...wdte.Func) wdte.Func {
+	return o
+}
+
+// member returns the method or field of the object with the given
+// name, or nil if there is no such member.
+func (o *object) member(name string) wdte.Func {
+	if m := o.v.MethodByName(name); m.IsValid() {
+		return Func(name, m.Interface())
+	}
+
+	s := reflect.Indirect(o.v)
+	if s.Kind() != reflect.Struct {
+		return nil
+	}
+	for _, f := range fields(s.Type()) {
+		if f.name == name {
+			return toWDTE(s.FieldByIndex(f.index))
+		}
+	}
+
+	return nil
+}
+
+func (o *object) At(i wdte.Func) (wdte.Func, error) {
+	name, ok := i.(wdte.String)
+	if !ok {
+		return nil, fmt.Errorf("object members must be accessed by string, not %v", i)
+	}
+
+	m := o.member(string(name))
+	if m == nil {
+		return nil, fmt.Errorf("%v has no member %q", o.v.Type(), name)
+	}
+	return m, nil
+}
+
+func (o *object) Scope() *wdte.Scope {
+	return wdte.S().Custom(
+		func(id wdte.ID) wdte.Func {
+			return o.member(string(id))
+		},
+		func(known map[wdte.ID]struct{}) {
+			t := o.v.Type()
+			for i := 0; i < t.NumMethod(); i++ {
+				known[wdte.ID(t.Method(i).Name)] = struct{}{}
+			}
+
+			if s := reflect.Indirect(o.v); s.Kind() == reflect.Struct {
+				for _, f := range fields(s.Type()) {
+					known[wdte.ID(f.name)] = struct{}{}
+				}
+			}
+		},
+	)
+}
+
+func (o *object) String() string {
+	return fmt.Sprintf("object(%v)", o.v.Type())
+}
+
+func (o *object) Reflect(name string) bool {
+	return (name == "Object") || (name == o.v.Type().String())
+}
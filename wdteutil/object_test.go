@@ -0,0 +1,85 @@
+package wdteutil_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DeedleFake/wdte"
+	"github.com/DeedleFake/wdte/std"
+	"github.com/DeedleFake/wdte/wdteutil"
+)
+
+type testClient struct {
+	Name  string `wdte:"name"`
+	calls int
+}
+
+func (c *testClient) Add(a, b int) int {
+	c.calls++
+	return a + b
+}
+
+func (c *testClient) Calls() int {
+	return c.calls
+}
+
+func (c *testClient) Fail() error {
+	return errors.New("failed")
+}
+
+func (c *testClient) Child(name string) *testClient {
+	return &testClient{Name: name}
+}
+
+func TestObject(t *testing.T) {
+	client := &testClient{Name: "main"}
+
+	scope := std.Scope.Map(map[wdte.ID]wdte.Func{
+		"client": wdteutil.Object(client),
+		"same": wdteutil.Func("same", func(c *testClient) bool {
+			return c == client
+		}),
+	})
+
+	tests := []struct {
+		name   string
+		script string
+		ret    wdte.Func
+	}{
+		{"Method", `client.Add 2 3;`, wdte.Number(5)},
+		{"Field", `client.name;`, wdte.String("main")},
+		{"NoArgs", `client.Add 1 1; client.Calls;`, wdte.Number(1)},
+		{"At", `(at client 'Add') 4 5;`, wdte.Number(9)},
+		{"Returned", `(client.Child 'child').name;`, wdte.String("child")},
+		{"Passed", `same client;`, wdte.Bool(true)},
+		{"Reflect", `reflect client 'Object';`, wdte.Bool(true)},
+		{"Error", `reflect client.Fail 'Error';`, wdte.Bool(true)},
+		{"Missing", `reflect (at client 'Missing') 'Error';`, wdte.Bool(true)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client.calls = 0
+
+			m, err := wdte.Parse(strings.NewReader(test.script), std.Import, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ret := m.Call(wdte.F().WithScope(scope))
+			if !wdte.Equal(ret, test.ret) {
+				t.Errorf("Expected %v, but got %v", test.ret, ret)
+			}
+		})
+	}
+
+	t.Run("Known", func(t *testing.T) {
+		s := wdteutil.Object(client).(wdte.Scoper).Scope()
+		expected := []wdte.ID{"Add", "Calls", "Child", "Fail", "name"}
+		if known := s.Known(); !reflect.DeepEqual(known, expected) {
+			t.Errorf("Expected %v, but got %v", expected, known)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package wdteutil
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// A field is a struct field that is visible to WDTE.
+type field struct {
+	name  string
+	index []int
+}
+
+var fieldCache sync.Map // map[reflect.Type][]field
+
+// fields returns the fields of the struct type t that are visible to
+// WDTE, along with the names that they are visible under. A field is
+// visible if it is exported and its wdte tag isn't "-". Its name is
+// taken from its wdte tag if it has one, and is otherwise the name of
+// the field. As with encoding/json, the fields of embedded structs
+// without tags are treated as though they were fields of the outer
+// struct, with fields of the outer struct taking precedence.
+func fields(t reflect.Type) []field {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.([]field)
+	}
+
+	var list []field
+	seen := make(map[string]struct{})
+	var collect func(t reflect.Type, index []int)
+	collect = func(t reflect.Type, index []int) {
+		var nested []reflect.StructField
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+
+			tag, tagged := f.Tag.Lookup("wdte")
+			if tag == "-" {
+				continue
+			}
+
+			if f.Anonymous && !tagged && (f.Type.Kind() == reflect.Struct) {
+				nested = append(nested, f)
+				continue
+			}
+
+			if f.PkgPath != "" {
+				continue
+			}
+
+			name := f.Name
+			if tag = strings.TrimSpace(tag); tag != "" {
+				name = tag
+			}
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+
+			list = append(list, field{
+				name:  name,
+				index: append(index[:len(index):len(index)], i),
+			})
+		}
+
+		for _, f := range nested {
+			collect(f.Type, append(index[:len(index):len(index)], f.Index...))
+		}
+	}
+	collect(t, nil)
+
+	f, _ := fieldCache.LoadOrStore(t, list)
+	return f.([]field)
+}
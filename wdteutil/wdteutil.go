@@ -1,8 +1,10 @@
 package wdteutil
 
 import (
-	"errors"
+	"context"
 	"fmt"
+	"math"
+	"math/big"
 	"reflect"
 
 	"github.com/DeedleFake/wdte"
@@ -12,6 +14,14 @@ var (
 	arrayType  = reflect.TypeOf(wdte.Array(nil))
 	numberType = reflect.TypeOf(wdte.Number(0))
 	stringType = reflect.TypeOf(wdte.String(""))
+	idType     = reflect.TypeOf(wdte.ID(""))
+	frameType  = reflect.TypeOf(wdte.Frame{})
+	bigIntType = reflect.TypeOf((*big.Int)(nil))
+	bigRatType = reflect.TypeOf((*big.Rat)(nil))
+
+	funcType    = reflect.TypeOf((*wdte.Func)(nil)).Elem()
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
 )
 
 func fromWDTE(frame wdte.Frame, w wdte.Func, expected reflect.Type) reflect.Value {
@@ -20,6 +30,17 @@ func fromWDTE(frame wdte.Frame, w wdte.Func, expected reflect.Type) reflect.Valu
 		return v
 	}
 
+	if o, ok := w.(*object); ok && o.v.Type().AssignableTo(expected) {
+		return o.v
+	}
+
+	if r, ok, err := fromNumeric(w, expected); ok {
+		if err != nil {
+			panic(err)
+		}
+		return r
+	}
+
 	switch expected.Kind() {
 	case reflect.Array:
 		v := v.Convert(arrayType).Interface().(wdte.Array)
@@ -38,7 +59,24 @@ func fromWDTE(frame wdte.Frame, w wdte.Func, expected reflect.Type) reflect.Valu
 		return FromFunc(frame, w, expected)
 
 	case reflect.Map:
-		panic(errors.New("map arguments are not yet supported"))
+		if expected.Key().Kind() != reflect.String {
+			panic(fmt.Errorf("map keys must be strings, not %v", expected.Key()))
+		}
+
+		s := toScope(w, expected)
+		r := reflect.MakeMap(expected)
+		for _, id := range s.Known() {
+			k := reflect.ValueOf(id).Convert(expected.Key())
+			r.SetMapIndex(k, fromWDTE(frame, s.Get(id).Call(frame), expected.Elem()))
+		}
+		return r
+
+	case reflect.Ptr:
+		if expected.Elem().Kind() == reflect.Struct {
+			r := reflect.New(expected.Elem())
+			r.Elem().Set(fromWDTE(frame, w, expected.Elem()))
+			return r
+		}
 
 	case reflect.Slice:
 		v := v.Convert(arrayType).Interface().(wdte.Array)
@@ -49,11 +87,37 @@ func fromWDTE(frame wdte.Frame, w wdte.Func, expected reflect.Type) reflect.Valu
 			r = reflect.Append(r, fromWDTE(frame, e, expected.Elem()))
 		}
 		return r
+
+	case reflect.Struct:
+		s := toScope(w, expected)
+		r := reflect.New(expected).Elem()
+		for _, f := range fields(expected) {
+			fv := s.Get(wdte.ID(f.name))
+			if fv == nil {
+				continue
+			}
+
+			dst := r.FieldByIndex(f.index)
+			if dst.Kind() != reflect.Func {
+				fv = fv.Call(frame)
+			}
+			dst.Set(fromWDTE(frame, fv, dst.Type()))
+		}
+		return r
 	}
 
 	return v.Convert(expected)
 }
 
+// toScope returns w as a scope, panicking if it isn't one.
+func toScope(w wdte.Func, expected reflect.Type) *wdte.Scope {
+	s, ok := w.(*wdte.Scope)
+	if !ok {
+		panic(fmt.Errorf("can't convert %T to %v: not a scope", w, expected))
+	}
+	return s
+}
+
 func toWDTE(v reflect.Value) wdte.Func {
 	if v, ok := v.Interface().(wdte.Func); ok {
 		return v
@@ -63,7 +127,17 @@ func toWDTE(v reflect.Value) wdte.Func {
 	case reflect.Bool:
 		return wdte.Bool(v.Bool())
 
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+	case reflect.Int64:
+		return wdte.Int(v.Int())
+
+	case reflect.Uint64:
+		u := v.Uint()
+		if u > math.MaxInt64 {
+			return wdte.BigInt{Int: new(big.Int).SetUint64(u)}
+		}
+		return wdte.Int(u)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uintptr, reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
 		return v.Convert(numberType).Interface().(wdte.Number)
 
 	case reflect.Array, reflect.Slice:
@@ -77,17 +151,166 @@ func toWDTE(v reflect.Value) wdte.Func {
 		return Func("<auto>", v.Interface())
 
 	case reflect.Map:
-		panic(errors.New("maps are not yet supported"))
+		if v.Type().Key().Kind() != reflect.String {
+			panic(fmt.Errorf("map keys must be strings, not %v", v.Type().Key()))
+		}
+
+		vars := make(map[wdte.ID]wdte.Func, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			id := iter.Key().Convert(idType).Interface().(wdte.ID)
+			vars[id] = toWDTE(iter.Value())
+		}
+		return wdte.S().Map(vars)
 
 	case reflect.Ptr:
+		switch v := v.Interface().(type) {
+		case *big.Int:
+			if v != nil {
+				return wdte.BigInt{Int: v}
+			}
+		case *big.Rat:
+			if v != nil {
+				return wdte.BigRat{Rat: v}
+			}
+		}
+
+		if v.IsNil() || (v.Type().NumMethod() > 0) {
+			return Object(v.Interface())
+		}
+		return toWDTE(v.Elem())
+
+	case reflect.Interface:
+		if v.IsNil() {
+			panic(fmt.Errorf("can't convert nil %v", v.Type()))
+		}
 		return toWDTE(v.Elem())
 
 	case reflect.String:
 		return v.Convert(stringType).Interface().(wdte.String)
 
 	case reflect.Struct:
-		panic(errors.New("structs are not yet supported"))
+		fields := fields(v.Type())
+		vars := make(map[wdte.ID]wdte.Func, len(fields))
+		for _, f := range fields {
+			vars[wdte.ID(f.name)] = toWDTE(v.FieldByIndex(f.index))
+		}
+		return wdte.S().Map(vars)
+	}
+
+	return Object(v.Interface())
+}
+
+// fromNumeric converts the numeric WDTE value w into the numeric Go
+// type expected, keeping the conversion exact wherever the types
+// involved allow it to be. If either w or expected isn't numeric, ok
+// is false. If the value of w doesn't fit in expected, err is the
+// error returned by checkNumeric.
+func fromNumeric(w wdte.Func, expected reflect.Type) (r reflect.Value, ok bool, err error) {
+	var i *big.Int
+	var f float64
+	switch w := w.(type) {
+	case wdte.Number:
+		f = float64(w)
+		i = new(big.Int)
+		if !math.IsNaN(f) && !math.IsInf(f, 0) {
+			big.NewFloat(f).Int(i)
+		}
+	case wdte.Int:
+		f = float64(w)
+		i = big.NewInt(int64(w))
+	case wdte.BigInt:
+		f, _ = new(big.Float).SetInt(w.Int).Float64()
+		i = w.Int
+	case wdte.BigRat:
+		f, _ = w.Float64()
+		i = new(big.Int).Quo(w.Num(), w.Denom())
+	default:
+		return r, false, nil
+	}
+
+	switch expected {
+	case bigIntType:
+		return reflect.ValueOf(new(big.Int).Set(i)), true, checkNumeric(w, expected)
+
+	case bigRatType:
+		if w, ok := w.(wdte.BigRat); ok {
+			return reflect.ValueOf(new(big.Rat).Set(w.Rat)), true, nil
+		}
+		if _, ok := w.(wdte.Number); ok {
+			return reflect.ValueOf(new(big.Rat).SetFloat64(f)), true, nil
+		}
+		return reflect.ValueOf(new(big.Rat).SetInt(i)), true, nil
+	}
+
+	switch expected.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		r = reflect.ValueOf(i.Int64()).Convert(expected)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		r = reflect.ValueOf(i.Uint64()).Convert(expected)
+
+	case reflect.Float32, reflect.Float64:
+		r = reflect.ValueOf(f).Convert(expected)
+
+	default:
+		return r, false, nil
+	}
+
+	return r, true, checkNumeric(w, expected)
+}
+
+// checkNumeric returns an error if converting the numeric WDTE value w
+// to the numeric Go type t would change it by more than rounding it to
+// the nearest float, such as if w is too large to fit in t or if t is
+// an integer type and w isn't an integer.
+func checkNumeric(w wdte.Func, t reflect.Type) error {
+	if t == bigRatType {
+		return nil
+	}
+
+	// r is the exact value of w, or nil if w is NaN or infinite.
+	var r *big.Rat
+	switch w := w.(type) {
+	case wdte.Int:
+		r = new(big.Rat).SetInt64(int64(w))
+	case wdte.BigInt:
+		r = new(big.Rat).SetInt(w.Int)
+	case wdte.BigRat:
+		r = w.Rat
+	case wdte.Number:
+		if !math.IsNaN(float64(w)) && !math.IsInf(float64(w), 0) {
+			r = new(big.Rat).SetFloat64(float64(w))
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if r == nil {
+			return nil
+		}
+		f, _ := r.Float64()
+		if math.IsInf(f, 0) || reflect.Zero(t).OverflowFloat(f) {
+			return fmt.Errorf("%v overflows %v", w, t)
+		}
+		return nil
+	}
+
+	if (r == nil) || !r.IsInt() {
+		return fmt.Errorf("%v is not an integer", w)
+	}
+
+	n := r.Num()
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !n.IsInt64() || reflect.Zero(t).OverflowInt(n.Int64()) {
+			return fmt.Errorf("%v overflows %v", w, t)
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if !n.IsUint64() || reflect.Zero(t).OverflowUint(n.Uint64()) {
+			return fmt.Errorf("%v overflows %v", w, t)
+		}
 	}
 
-	panic(fmt.Errorf("unsupported type: %T", v))
+	return nil
 }